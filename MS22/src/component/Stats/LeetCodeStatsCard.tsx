@@ -25,6 +25,9 @@ interface LeetCodeStatsCardProps {
   leetcode: LeetCodeData;
 }
 
+const solvedCount = (leetcode: LeetCodeData, difficulty: string) =>
+  leetcode.solved.find((s) => s.difficulty === difficulty)?.count || 0;
+
 export const LeetCodeStatsCard = ({ leetcode }: LeetCodeStatsCardProps) => (
   <div className="bg-linear-to-br from-gray-900/50 to-gray-950/50 backdrop-blur-sm border border-gray-800/50 rounded-2xl p-6 hover:border-cyan-500/40 transition-all duration-300">
     <div className="flex items-center gap-3 mb-6">
@@ -38,12 +41,12 @@ export const LeetCodeStatsCard = ({ leetcode }: LeetCodeStatsCardProps) => (
     </div>
 
     <div className="space-y-3">
-      {leetcode.profile.realName && (
+      {leetcode.real_name && (
         <div className="p-3 bg-gray-800/30 rounded-lg border border-gray-700/50">
           <div className="flex items-center gap-2">
             <User className="w-4 h-4 text-cyan-400" />
             <span className="text-sm text-gray-300">
-              {leetcode.profile.realName}
+              {leetcode.real_name}
             </span>
           </div>
         </div>
@@ -55,7 +58,7 @@ export const LeetCodeStatsCard = ({ leetcode }: LeetCodeStatsCardProps) => (
           <div>
             <p className="text-xs text-gray-400">Global Ranking</p>
             <p className="text-lg font-bold text-white">
-              #{leetcode.profile?.ranking?.toLocaleString() || "N/A"}
+              #{leetcode.ranking?.toLocaleString() || "N/A"}
             </p>
           </div>
         </div>
@@ -64,17 +67,17 @@ export const LeetCodeStatsCard = ({ leetcode }: LeetCodeStatsCardProps) => (
       <div className="space-y-2">
         <DifficultyCard
           difficulty="Easy"
-          count={leetcode.submitStats.acSubmissionNum[1]?.count || 0}
+          count={solvedCount(leetcode, "Easy")}
           color="bg-green-500"
         />
         <DifficultyCard
           difficulty="Medium"
-          count={leetcode.submitStats.acSubmissionNum[2]?.count || 0}
+          count={solvedCount(leetcode, "Medium")}
           color="bg-yellow-500"
         />
         <DifficultyCard
           difficulty="Hard"
-          count={leetcode.submitStats.acSubmissionNum[3]?.count || 0}
+          count={solvedCount(leetcode, "Hard")}
           color="bg-red-500"
         />
       </div>
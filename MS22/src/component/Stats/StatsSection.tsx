@@ -1,11 +1,18 @@
 import { Suspense } from "react";
 import { API_BASE_URL } from "@/constants/url";
+import type {
+  GitHubData,
+  LeetCodeData,
+  Repository,
+  StatsResponse,
+} from "@/types/stats";
 import { CommitsActivityCard } from "./CommitsActivityCard";
 import { GitHubProfileCard } from "./GitHubProfileCard";
 import { LeetCodeStatsCard } from "./LeetCodeStatsCard";
 import { TopRepositoriesCard } from "./TopRepositoriesCard";
 
-async function fetchWithTimeout(url: string, ms = 8000) {
+// Resolves to the envelope's data, or null when the request fails
+async function fetchWithTimeout<T>(url: string, ms = 8000): Promise<T | null> {
   const controller = new AbortController();
   const timeoutId = setTimeout(() => controller.abort(), ms);
 
@@ -16,7 +23,8 @@ async function fetchWithTimeout(url: string, ms = 8000) {
     });
     clearTimeout(timeoutId);
     if (!res.ok) return null;
-    return res.json();
+    const body: StatsResponse<T> = await res.json();
+    return body.data ?? null;
   } catch {
     clearTimeout(timeoutId);
     return null;
@@ -25,8 +33,8 @@ async function fetchWithTimeout(url: string, ms = 8000) {
 
 async function GitHubProfileSection() {
   const [gh, starsData] = await Promise.all([
-    fetchWithTimeout(`${API_BASE_URL}/api/github`),
-    fetchWithTimeout(`${API_BASE_URL}/api/github/stars`),
+    fetchWithTimeout<GitHubData>(`${API_BASE_URL}/api/github`),
+    fetchWithTimeout<{ stars: number }>(`${API_BASE_URL}/api/github/stars`),
   ]);
 
   if (!gh) return null;
@@ -35,17 +43,21 @@ async function GitHubProfileSection() {
 }
 
 async function LeetCodeSection() {
-  const lc = await fetchWithTimeout(`${API_BASE_URL}/api/leetcode`);
+  const lc = await fetchWithTimeout<LeetCodeData>(
+    `${API_BASE_URL}/api/leetcode`,
+  );
 
-  if (!lc?.data?.matchedUser) return null;
+  if (!lc?.solved) return null;
 
-  return <LeetCodeStatsCard leetcode={lc.data.matchedUser} />;
+  return <LeetCodeStatsCard leetcode={lc} />;
 }
 
 async function CommitsSection() {
   const [commits, cal] = await Promise.all([
-    fetchWithTimeout(`${API_BASE_URL}/api/github/commits`),
-    fetchWithTimeout(`${API_BASE_URL}/api/github/calendar`),
+    fetchWithTimeout<Array<{ date: string; count: number }>>(
+      `${API_BASE_URL}/api/github/commits`,
+    ),
+    fetchWithTimeout<unknown>(`${API_BASE_URL}/api/github/calendar`),
   ]);
 
   if (!commits || commits.length === 0) return null;
@@ -54,7 +66,9 @@ async function CommitsSection() {
 }
 
 async function TopReposSection() {
-  const top = await fetchWithTimeout(`${API_BASE_URL}/api/github/top-repos`);
+  const top = await fetchWithTimeout<Repository[]>(
+    `${API_BASE_URL}/api/github/top-repos`,
+  );
 
   if (!top || top.length === 0) return null;
 
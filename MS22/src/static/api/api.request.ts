@@ -8,6 +8,9 @@ import type {
   ExperiencesResponse,
   Project,
   ProjectsResponse,
+  RelatedItemsResponse,
+  SearchClick,
+  SearchMode,
   SearchResponse,
   SearchResultType,
   SearchSuggestionsResponse,
@@ -195,14 +198,18 @@ export const searchAPI = {
    * @param query - Search query string
    * @param type - Optional filter by content type
    * @param limit - Max results to return (default 10, max 50)
+   * @param year - Optional year or inclusive range, e.g. "2023" or "2021..2023"
+   * @param mode - "keyword" (default), "semantic" or "hybrid"
    */
   search: async (
     query: string,
     type?: SearchResultType,
     limit: number = 10,
+    year?: string,
+    mode?: SearchMode,
   ): Promise<ApiResponse<SearchResponse>> => {
     const response = await api.get<ApiResponse<SearchResponse>>("/search", {
-      params: { q: query, type, limit },
+      params: { q: query, type, limit, year, mode },
     });
     return response.data;
   },
@@ -222,6 +229,33 @@ export const searchAPI = {
     );
     return response.data;
   },
+  /**
+   * Report that a search result was opened, for click-through analytics
+   * @param click - The query_id of the search and the clicked result
+   */
+  recordClick: async (click: SearchClick): Promise<void> => {
+    await api.post("/search/click", click);
+  },
+
+  /**
+   * Get the items most similar to a project, experience, certificate or volunteer entry
+   * @param type - Type of the source item
+   * @param id - ID of the source item
+   * @param limit - Max items to return (default 5, max 20)
+   */
+  getRelated: async (
+    type: SearchResultType,
+    id: string,
+    limit: number = 5,
+  ): Promise<ApiResponse<RelatedItemsResponse>> => {
+    const response = await api.get<ApiResponse<RelatedItemsResponse>>(
+      `/related/${type}/${id}`,
+      {
+        params: { limit },
+      },
+    );
+    return response.data;
+  },
 };
 
 // ─── React.cache() wrappers for request deduplication ───
@@ -39,6 +39,19 @@ export interface TimelineResponse {
   experiences?: TimelineItem[];
 }
 
+export interface RepoStats {
+  repository: string;
+  forge: "github" | "gitlab" | "gitea";
+  full_name: string;
+  stars: number;
+  forks: number;
+  open_issues: number;
+  pushed_at: string;
+  language: string;
+  topics: string[] | null;
+  fetched_at: string;
+}
+
 export interface Project {
   inline?: {
     id?: string;
@@ -53,6 +66,7 @@ export interface Project {
   project_live_link?: string;
   small_description: string;
   project_repository?: string;
+  repo_stats?: RepoStats;
 }
 
 export interface ProjectsResponse {
@@ -165,12 +179,26 @@ export interface ExperiencesResponse {
 }
 
 // Search Types
+export type SearchMode = "keyword" | "semantic" | "hybrid";
+
 export type SearchResultType =
   | "project"
   | "experience"
   | "certificate"
   | "volunteer";
 
+// Offsets count UTF-16 code units, so text.slice(start, end) is the match; end exclusive.
+export interface HighlightSpan {
+  start: number;
+  end: number;
+}
+
+export interface SearchHighlights {
+  title?: HighlightSpan[];
+  subtitle?: HighlightSpan[];
+  description?: HighlightSpan[];
+}
+
 export interface SearchResult {
   id: string;
   type: SearchResultType;
@@ -178,16 +206,68 @@ export interface SearchResult {
   subtitle?: string;
   description: string;
   skills?: string[];
+  highlights?: SearchHighlights;
   score: number;
   url: string;
 }
 
+export interface FacetCount {
+  value: string;
+  count: number;
+}
+
+export interface SearchFacets {
+  types: FacetCount[];
+  skills: FacetCount[];
+}
+
 export interface SearchResponse {
   results: SearchResult[];
+  facets?: SearchFacets;
   query: string;
+  did_you_mean?: string;
+  // Echo back in searchAPI.recordClick when a result is opened.
+  query_id?: string;
+  mode: SearchMode;
   total_count: number;
 }
 
+export interface RelatedItem {
+  id: string;
+  type: SearchResultType;
+  title: string;
+  subtitle?: string;
+  description: string;
+  skills?: string[];
+  shared_skills?: string[];
+  score: number;
+  url: string;
+}
+
+export interface RelatedItemsResponse {
+  related: RelatedItem[];
+}
+
+export interface SearchClick {
+  query_id: string;
+  result_id: string;
+  result_type?: SearchResultType;
+  position: number;
+}
+
+export type SearchSuggestionType =
+  | "skill"
+  | "project"
+  | "company"
+  | "certificate"
+  | "organisation";
+
+export interface SearchSuggestion {
+  text: string;
+  type: SearchSuggestionType;
+  score: number;
+}
+
 export interface SearchSuggestionsResponse {
-  suggestions: string[];
+  suggestions: SearchSuggestion[];
 }
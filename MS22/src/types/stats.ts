@@ -12,30 +12,31 @@ export interface GitHubData {
   html_url: string;
 }
 
+// One of /api/github/top-repos, which spans every forge account
 export interface Repository {
+  forge: string;
   name: string;
   description: string;
   html_url: string;
   stargazers_count: number;
   language: string;
-  forks_count: number;
-  open_issues_count: number;
-  created_at: string;
-  updated_at: string;
 }
 
 // LeetCode Types
 export interface LeetCodeData {
-  profile: {
-    realName: string;
-    ranking: number;
-    userAvatar: string;
-  };
-  submitStats: {
-    acSubmissionNum: Array<{
-      difficulty: string;
-      count: number;
-      submissions: number;
-    }>;
-  };
+  username: string;
+  real_name: string;
+  avatar: string;
+  ranking: number;
+  solved: Array<{
+    difficulty: string;
+    count: number;
+  }>;
+}
+
+// Every stats endpoint wraps its payload in this envelope
+export interface StatsResponse<T> {
+  status: number;
+  message: string;
+  data: T;
 }
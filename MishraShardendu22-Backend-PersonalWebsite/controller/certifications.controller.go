@@ -1,12 +1,17 @@
 package controller
 
 import (
+	"errors"
+	"time"
+
 	"github.com/MishraShardendu22/models"
 	"github.com/MishraShardendu22/util"
 	"github.com/gofiber/fiber/v2"
 	"github.com/kamva/mgm/v3"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 func GetCertifications(c *fiber.Ctx) error {
@@ -101,7 +106,7 @@ func AddCertification(c *fiber.Ctx) error {
 		return util.ResponseAPI(c, fiber.StatusBadRequest, "Title, description, and issuer are required", nil, "")
 	}
 
-	cert.Tokens = util.GenerateTokens([]string{cert.Title, cert.Issuer, cert.Description}, cert.Skills)
+	cert.Tokens = certificationTokens(&cert)
 
 	if err := mgm.Coll(&models.CertificationOrAchievements{}).Create(&cert); err != nil {
 		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to add certification", nil, "")
@@ -117,7 +122,7 @@ func AddCertification(c *fiber.Ctx) error {
 		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to update user certifications", nil, "")
 	}
 
-	InvalidateSearchCache()
+	upsertSearchDocument(certificationSearchDocument(&cert))
 	return util.ResponseAPI(c, fiber.StatusOK, "Certification added successfully", cert, "")
 }
 
@@ -141,7 +146,7 @@ func UpdateCertification(c *fiber.Ctx) error {
 		return util.ResponseAPI(c, fiber.StatusBadRequest, "Title, description, and issuer are required", nil, "")
 	}
 
-	tokens := util.GenerateTokens([]string{input.Title, input.Issuer, input.Description}, input.Skills)
+	tokens := certificationTokens(&input)
 
 	update := bson.M{"$set": bson.M{
 		"title":           input.Title,
@@ -154,14 +159,25 @@ func UpdateCertification(c *fiber.Ctx) error {
 		"issue_date":      input.IssueDate,
 		"expiry_date":     input.ExpiryDate,
 		"tokens":          tokens,
+		"updated_at":      time.Now(),
 	}}
 
-	if _, err := mgm.Coll(&models.CertificationOrAchievements{}).UpdateByID(c.Context(), certObjID, update); err != nil {
+	// The search document is built from the stored certification, which has
+	// the fields the body does not carry, like created_at.
+	var updated models.CertificationOrAchievements
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = mgm.Coll(&models.CertificationOrAchievements{}).
+		FindOneAndUpdate(c.Context(), bson.M{"_id": certObjID}, update, opts).
+		Decode(&updated)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return util.ResponseAPI(c, fiber.StatusNotFound, "Certification not found", nil, "")
+	}
+	if err != nil {
 		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to update certification", nil, "")
 	}
 
-	InvalidateSearchCache()
-	return util.ResponseAPI(c, fiber.StatusOK, "Certification updated successfully", input, "")
+	upsertSearchDocument(certificationSearchDocument(&updated))
+	return util.ResponseAPI(c, fiber.StatusOK, "Certification updated successfully", updated, "")
 }
 
 func RemoveCertification(c *fiber.Ctx) error {
@@ -201,6 +217,6 @@ func RemoveCertification(c *fiber.Ctx) error {
 		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to delete certification", nil, "")
 	}
 
-	InvalidateSearchCache()
+	removeSearchDocument(certObjID.Hex())
 	return util.ResponseAPI(c, fiber.StatusOK, "Certification removed successfully", nil, "")
 }
@@ -94,7 +94,7 @@ func AddExperiences(c *fiber.Ctx) error {
 		return util.ResponseAPI(c, fiber.StatusBadRequest, "Company name, position and start date are required", nil, "")
 	}
 
-	e.Tokens = util.GenerateTokens([]string{e.CompanyName, e.Description}, e.Technologies)
+	e.Tokens = experienceTokens(&e)
 
 	if err := mgm.Coll(&models.Experience{}).Create(&e); err != nil {
 		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to add experience", nil, "")
@@ -110,7 +110,7 @@ func AddExperiences(c *fiber.Ctx) error {
 		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to update user experiences", nil, "")
 	}
 
-	InvalidateSearchCache()
+	upsertSearchDocument(experienceSearchDocument(&e))
 	return util.ResponseAPI(c, fiber.StatusOK, "Experience added successfully", e, "")
 }
 
@@ -149,13 +149,13 @@ func UpdateExperiences(c *fiber.Ctx) error {
 	existing.CertificateURL = input.CertificateURL
 	existing.Images = input.Images
 
-	existing.Tokens = util.GenerateTokens([]string{existing.CompanyName, existing.Description}, existing.Technologies)
+	existing.Tokens = experienceTokens(&existing)
 
 	if err := mgm.Coll(&models.Experience{}).Update(&existing); err != nil {
 		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to update experience", nil, "")
 	}
 
-	InvalidateSearchCache()
+	upsertSearchDocument(experienceSearchDocument(&existing))
 	return util.ResponseAPI(c, fiber.StatusOK, "Experience updated successfully", existing, "")
 }
 
@@ -200,6 +200,6 @@ func RemoveExperiences(c *fiber.Ctx) error {
 		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to delete experience", nil, "")
 	}
 
-	InvalidateSearchCache()
+	removeSearchDocument(expObjID.Hex())
 	return util.ResponseAPI(c, fiber.StatusOK, "Experience removed successfully", nil, "")
 }
@@ -0,0 +1,24 @@
+package controller
+
+import (
+	"github.com/MishraShardendu22/outbound"
+	"github.com/MishraShardendu22/util"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetHealth reports the circuit breaker of every upstream host called so
+// far. The service is degraded while any breaker is not closed; it still
+// answers 200, as cached stats keep being served.
+func GetHealth(c *fiber.Ctx) error {
+	hosts := outboundTransport.Hosts()
+	status := "ok"
+	for _, host := range hosts {
+		if host.State != outbound.StateClosed {
+			status = "degraded"
+		}
+	}
+	return util.ResponseAPI(c, fiber.StatusOK, "Service health", fiber.Map{
+		"status":   status,
+		"outbound": hosts,
+	}, "")
+}
@@ -2,7 +2,9 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"sort"
+	"time"
 
 	"github.com/MishraShardendu22/models"
 	"github.com/MishraShardendu22/util"
@@ -10,6 +12,8 @@ import (
 	"github.com/kamva/mgm/v3"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 func GetProjects(c *fiber.Ctx) error {
@@ -58,6 +62,9 @@ func GetProjects(c *fiber.Ctx) error {
 	}
 
 	paginatedProjects := projects[startIndex:endIndex]
+	for i := range paginatedProjects {
+		hideStaleRepoStats(&paginatedProjects[i])
+	}
 
 	return util.ResponseAPI(c, fiber.StatusOK, "Projects retrieved successfully", fiber.Map{
 		"projects":     paginatedProjects,
@@ -83,6 +90,7 @@ func GetProjectByID(c *fiber.Ctx) error {
 	if err := mgm.Coll(&models.Project{}).FindByID(projObjID, &p); err != nil {
 		return util.ResponseAPI(c, fiber.StatusNotFound, "Project not found", nil, "")
 	}
+	hideStaleRepoStats(&p)
 	return util.ResponseAPI(c, fiber.StatusOK, "Project retrieved successfully", p, "")
 }
 
@@ -95,7 +103,8 @@ func AddProjects(c *fiber.Ctx) error {
 		return util.ResponseAPI(c, fiber.StatusBadRequest, "Name, small description and description are required", nil, "")
 	}
 
-	p.Tokens = util.GenerateTokens([]string{p.ProjectName, p.Description, p.SmallDescription}, p.Skills)
+	p.Tokens = projectTokens(&p)
+	p.RepoStats = nil
 
 	if err := mgm.Coll(&models.Project{}).Create(&p); err != nil {
 		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to add project", nil, "")
@@ -110,7 +119,8 @@ func AddProjects(c *fiber.Ctx) error {
 		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to update user projects", nil, "")
 	}
 
-	InvalidateSearchCache()
+	upsertSearchDocument(projectSearchDocument(&p))
+	enrichProjectAsync(p)
 	return util.ResponseAPI(c, fiber.StatusOK, "Project added successfully", p, "")
 }
 
@@ -132,7 +142,7 @@ func UpdateProjects(c *fiber.Ctx) error {
 		return util.ResponseAPI(c, fiber.StatusBadRequest, "Name, small description and description are required", nil, "")
 	}
 
-	tokens := util.GenerateTokens([]string{input.ProjectName, input.Description, input.SmallDescription}, input.Skills)
+	tokens := projectTokens(&input)
 
 	update := bson.M{"$set": bson.M{
 		"project_name":       input.ProjectName,
@@ -143,13 +153,26 @@ func UpdateProjects(c *fiber.Ctx) error {
 		"project_live_link":  input.ProjectLiveLink,
 		"project_video":      input.ProjectVideo,
 		"tokens":             tokens,
+		"updated_at":         time.Now(),
 	}}
-	if _, err := mgm.Coll(&models.Project{}).UpdateByID(c.Context(), projObjID, update); err != nil {
+	// The search document is built from the stored project, which has the
+	// fields the body does not carry, like created_at.
+	var updated models.Project
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = mgm.Coll(&models.Project{}).
+		FindOneAndUpdate(c.Context(), bson.M{"_id": projObjID}, update, opts).
+		Decode(&updated)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return util.ResponseAPI(c, fiber.StatusNotFound, "Project not found", nil, "")
+	}
+	if err != nil {
 		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to update project", nil, "")
 	}
 
-	InvalidateSearchCache()
-	return util.ResponseAPI(c, fiber.StatusOK, "Project updated successfully", input, "")
+	upsertSearchDocument(projectSearchDocument(&updated))
+	enrichProjectAsync(updated)
+	hideStaleRepoStats(&updated)
+	return util.ResponseAPI(c, fiber.StatusOK, "Project updated successfully", updated, "")
 }
 
 func RemoveProjects(c *fiber.Ctx) error {
@@ -186,7 +209,7 @@ func RemoveProjects(c *fiber.Ctx) error {
 		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to delete project", nil, "")
 	}
 
-	InvalidateSearchCache()
+	removeSearchDocument(objID.Hex())
 	return util.ResponseAPI(c, fiber.StatusOK, "Project removed successfully", nil, "")
 }
 
@@ -0,0 +1,244 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MishraShardendu22/models"
+	"github.com/MishraShardendu22/util"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kamva/mgm/v3"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	repoStatsRunTimeout     = 5 * time.Minute
+	repoStatsProjectTimeout = 30 * time.Second
+)
+
+var repoPathSegmentPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+var errRepoUnsupported = errors.New("repository link is not on a known forge")
+
+// Only one refresh runs at a time; its progress stays readable afterwards.
+var (
+	repoStatsStatus models.RepoStatsRefresh
+	repoStatsMutex  sync.Mutex
+)
+
+// StartRepoStatsEnricher refreshes the repository stats of every project
+// every interval until ctx is done. Projects refreshed less than interval
+// ago, for example by an admin trigger, are left alone.
+func StartRepoStatsEnricher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if beginRepoStatsRun() {
+			runRepoStatsRefresh(ctx, interval*9/10)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func beginRepoStatsRun() bool {
+	repoStatsMutex.Lock()
+	defer repoStatsMutex.Unlock()
+	if repoStatsStatus.Running {
+		return false
+	}
+	repoStatsStatus = models.RepoStatsRefresh{StartedAt: time.Now(), Running: true}
+	return true
+}
+
+// runRepoStatsRefresh refreshes every project whose stats are older than
+// maxAge or were fetched for another link.
+func runRepoStatsRefresh(ctx context.Context, maxAge time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, repoStatsRunTimeout)
+	defer cancel()
+
+	var projects []models.Project
+	err := mgm.Coll(&models.Project{}).SimpleFindWithCtx(ctx, &projects,
+		bson.M{"project_repository": bson.M{"$nin": bson.A{"", nil}}})
+
+	for i := 0; err == nil && i < len(projects); i++ {
+		p := &projects[i]
+		if stats := p.RepoStats; stats != nil && stats.Repository == p.ProjectRepository &&
+			time.Since(stats.FetchedAt) < maxAge {
+			countRepoStats(func(s *models.RepoStatsRefresh) { s.Fresh++ })
+			continue
+		}
+
+		switch perr := enrichProject(ctx, p); {
+		case perr == nil:
+			countRepoStats(func(s *models.RepoStatsRefresh) { s.Updated++ })
+		case errors.Is(perr, errRepoUnsupported):
+			countRepoStats(func(s *models.RepoStatsRefresh) { s.Unsupported++ })
+		case ctx.Err() != nil:
+			err = ctx.Err()
+		default:
+			slog.Warn("Failed to refresh repository stats", "project", p.ID.Hex(),
+				"repository", p.ProjectRepository, "error", perr)
+			countRepoStats(func(s *models.RepoStatsRefresh) { s.Failed++ })
+		}
+	}
+
+	repoStatsMutex.Lock()
+	repoStatsStatus.Running = false
+	repoStatsStatus.FinishedAt = time.Now()
+	if err != nil {
+		repoStatsStatus.Error = err.Error()
+	}
+	status := repoStatsStatus
+	repoStatsMutex.Unlock()
+
+	if err != nil {
+		slog.Error("Repository stats refresh failed", "error", err)
+		return
+	}
+	slog.Info("Repository stats refreshed",
+		"updated", status.Updated,
+		"failed", status.Failed,
+		"duration", status.FinishedAt.Sub(status.StartedAt),
+	)
+}
+
+func countRepoStats(count func(*models.RepoStatsRefresh)) {
+	repoStatsMutex.Lock()
+	count(&repoStatsStatus)
+	repoStatsMutex.Unlock()
+}
+
+// enrichProject fetches the stats of p's repository and stores them on p.
+func enrichProject(ctx context.Context, p *models.Project) error {
+	provider, path, err := repoProvider(p.ProjectRepository)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, repoStatsProjectTimeout)
+	defer cancel()
+	stats, err := provider.Repository(ctx, path)
+	if err != nil {
+		return err
+	}
+	stats.Repository = p.ProjectRepository
+	stats.FetchedAt = time.Now()
+
+	// Match the link too, so stats never land on a project whose link
+	// changed while they were fetched.
+	filter := bson.M{"_id": p.ID, "project_repository": p.ProjectRepository}
+	if _, err := mgm.Coll(&models.Project{}).UpdateOne(ctx, filter,
+		bson.M{"$set": bson.M{"repo_stats": stats}}); err != nil {
+		return err
+	}
+	p.RepoStats = &stats
+	return nil
+}
+
+// enrichProjectAsync refreshes one project's stats after an admin edit.
+func enrichProjectAsync(p models.Project) {
+	if p.ProjectRepository == "" {
+		return
+	}
+	go func() {
+		if err := enrichProject(context.Background(), &p); err != nil && !errors.Is(err, errRepoUnsupported) {
+			slog.Warn("Failed to fetch repository stats", "project", p.ID.Hex(), "error", err)
+		}
+	}()
+}
+
+// repoProvider finds the forge serving a repository link and the path the
+// forge knows the repository by. Configured forge accounts are matched
+// first, then the public GitHub, GitLab and Codeberg.
+func repoProvider(link string) (ForgeProvider, string, error) {
+	host, path, ok := util.ParseRepoURL(link)
+	if !ok {
+		return nil, "", errRepoUnsupported
+	}
+
+	candidates := append(statsAccounts(),
+		models.ForgeAccount{Kind: forgeGitHub},
+		models.ForgeAccount{Kind: forgeGitLab},
+		models.ForgeAccount{Kind: forgeGitea},
+	)
+	for _, account := range candidates {
+		baseURL := account.BaseURL
+		if baseURL == "" {
+			baseURL = defaultForgeBaseURLs[account.Kind]
+		}
+		// GitHub serves its API from api.<host>, the others from the host.
+		apiHost := util.URLHostname(baseURL)
+		if apiHost != host && apiHost != "api."+host {
+			continue
+		}
+
+		repoPath, ok := forgeRepoPath(account.Kind, path)
+		if !ok {
+			return nil, "", errRepoUnsupported
+		}
+		provider, err := newForgeProvider(account, httpClient)
+		return provider, repoPath, err
+	}
+	return nil, "", errRepoUnsupported
+}
+
+// forgeRepoPath trims a repository link's path to the repository itself:
+// owner/name on GitHub and Gitea, group/.../name on GitLab, whose pages
+// below a repository start with "/-/".
+func forgeRepoPath(kind, path string) (string, bool) {
+	if kind == forgeGitLab {
+		path, _, _ = strings.Cut(path, "/-/")
+	}
+	segments := strings.Split(path, "/")
+	if kind != forgeGitLab && len(segments) > 2 {
+		segments = segments[:2]
+	}
+
+	if len(segments) < 2 {
+		return "", false
+	}
+	for _, segment := range segments {
+		if segment == "." || segment == ".." || !repoPathSegmentPattern.MatchString(segment) {
+			return "", false
+		}
+	}
+	return strings.Join(segments, "/"), true
+}
+
+// hideStaleRepoStats drops stats fetched for a link the project no longer
+// has.
+func hideStaleRepoStats(p *models.Project) {
+	if p.RepoStats != nil && p.RepoStats.Repository != p.ProjectRepository {
+		p.RepoStats = nil
+	}
+}
+
+func StartRepoStatsRefresh(c *fiber.Ctx) error {
+	if !beginRepoStatsRun() {
+		return util.ResponseAPI(c, fiber.StatusConflict, "Repository stats refresh already running", repoStatsSnapshot(), "")
+	}
+
+	go runRepoStatsRefresh(context.Background(), 0)
+	return util.ResponseAPI(c, fiber.StatusAccepted, "Repository stats refresh started", repoStatsSnapshot(), "")
+}
+
+func GetRepoStatsRefresh(c *fiber.Ctx) error {
+	return util.ResponseAPI(c, fiber.StatusOK, "Repository stats refresh status", repoStatsSnapshot(), "")
+}
+
+func repoStatsSnapshot() models.RepoStatsRefresh {
+	repoStatsMutex.Lock()
+	defer repoStatsMutex.Unlock()
+	return repoStatsStatus
+}
@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"math"
+	"sort"
+
+	"github.com/MishraShardendu22/models"
+	"github.com/MishraShardendu22/util"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Related items are ranked by the cosine of their TF-IDF token vectors,
+// blended with the Jaccard overlap of their skills.
+const (
+	relatedTextWeight   = 0.7
+	relatedSkillWeight  = 0.3
+	defaultRelatedLimit = 5
+	maxRelatedLimit     = 20
+)
+
+// tfidfVector weights the stored tokens of a document with a sublinear term
+// frequency and returns the vector with its euclidean norm.
+func tfidfVector(idx *invertedIndex, tokens []string) (map[string]float64, float64) {
+	counts := make(map[string]int, len(tokens))
+	for _, token := range tokens {
+		counts[token]++
+	}
+
+	vector := make(map[string]float64, len(counts))
+	var sumSquares float64
+	for token, tf := range counts {
+		w := (1 + math.Log(float64(tf))) * idx.idf[token]
+		if w == 0 {
+			continue
+		}
+		vector[token] = w
+		sumSquares += w * w
+	}
+	return vector, math.Sqrt(sumSquares)
+}
+
+func cosineSimilarity(a map[string]float64, normA float64, b map[string]float64, normB float64) float64 {
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	var dot float64
+	for token, w := range a {
+		dot += w * b[token]
+	}
+	return dot / (normA * normB)
+}
+
+// sharedSkills returns the skills of b that a also lists, and their Jaccard
+// overlap.
+func sharedSkills(a, b []string) ([]string, float64) {
+	set := make(map[string]struct{}, len(a))
+	for _, skill := range uniqueFold(a) {
+		set[normalizeSkill(skill)] = struct{}{}
+	}
+
+	unique := uniqueFold(b)
+	shared := make([]string, 0, len(unique))
+	for _, skill := range unique {
+		if _, ok := set[normalizeSkill(skill)]; ok {
+			shared = append(shared, skill)
+		}
+	}
+
+	union := len(set) + len(unique) - len(shared)
+	if union == 0 {
+		return shared, 0
+	}
+	return shared, float64(len(shared)) / float64(union)
+}
+
+func GetRelatedItems(c *fiber.Ctx) error {
+	docType, ok := searchTypeAliases[c.Params("type")]
+	if !ok {
+		return util.ResponseAPI(c, fiber.StatusBadRequest, "Invalid type", nil, "")
+	}
+	id := c.Params("id")
+	if id == "" {
+		return util.ResponseAPI(c, fiber.StatusBadRequest, "ID is required", nil, "")
+	}
+
+	limit := c.QueryInt("limit", defaultRelatedLimit)
+	if limit < 1 || limit > maxRelatedLimit {
+		limit = defaultRelatedLimit
+	}
+
+	idx, release, err := acquireSearchIndex()
+	if err != nil {
+		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to get search index", nil, "")
+	}
+	defer release()
+
+	target, ok := idx.docs[id]
+	if !ok || target.doc.Type != docType {
+		return util.ResponseAPI(c, fiber.StatusNotFound, "Item not found", nil, "")
+	}
+
+	type relatedMatch struct {
+		entry  *indexedDocument
+		shared []string
+		score  float64
+	}
+
+	targetVector, targetNorm := tfidfVector(idx, target.doc.Tokens)
+	matches := make([]relatedMatch, 0, len(idx.docs))
+	for otherID, entry := range idx.docs {
+		if otherID == id {
+			continue
+		}
+
+		vector, norm := tfidfVector(idx, entry.doc.Tokens)
+		shared, overlap := sharedSkills(target.doc.Skills, entry.doc.Skills)
+		score := relatedTextWeight*cosineSimilarity(targetVector, targetNorm, vector, norm) +
+			relatedSkillWeight*overlap
+		if score > 0 {
+			matches = append(matches, relatedMatch{entry: entry, shared: shared, score: score})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].entry.doc.ID < matches[j].entry.doc.ID
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	related := make([]models.RelatedItem, 0, len(matches))
+	for _, m := range matches {
+		doc := &m.entry.doc
+		description, _ := buildSnippet(doc.Description, nil)
+		related = append(related, models.RelatedItem{
+			ID:           doc.ID,
+			Type:         doc.Type,
+			Title:        doc.Title,
+			Subtitle:     doc.Subtitle,
+			Description:  description,
+			Skills:       doc.Skills,
+			SharedSkills: m.shared,
+			URL:          doc.URL,
+			Score:        math.Round(m.score*100) / 100,
+		})
+	}
+
+	return util.ResponseAPI(c, fiber.StatusOK, "Related items retrieved successfully", fiber.Map{
+		"related": related,
+	}, "")
+}
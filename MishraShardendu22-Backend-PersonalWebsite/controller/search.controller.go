@@ -1,7 +1,9 @@
 package controller
 
 import (
+	"log/slog"
 	"math"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -14,17 +16,14 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 )
 
-const (
-	bm25K1        = 1.2
-	bm25B         = 0.75
-	indexCacheTTL = 5 * time.Minute
-)
+const indexCacheTTL = 5 * time.Minute
 
-// In-memory cache for document index
+// In-memory inverted index, rebuilt from Mongo after indexCacheTTL and
+// patched in place by the write handlers in between.
 var (
-	cachedIndex     []models.SearchDocument
-	cacheTimestamp  time.Time
-	cacheMutex      sync.RWMutex
+	searchIdx      *invertedIndex
+	cacheTimestamp time.Time
+	cacheMutex     sync.RWMutex
 )
 
 type scoredDocument struct {
@@ -32,218 +31,198 @@ type scoredDocument struct {
 	doc   *models.SearchDocument
 }
 
-func calculateBM25Score(
-	doc *models.SearchDocument,
-	queryTokens []string,
-	avgDocLength float64,
-	docCount int,
-	docFrequencies map[string]int,
-	termFreq map[string]int,
+// calculateBM25FScore combines the per-field term frequencies, each weighted
+// and length-normalised against its own field average, before applying the
+// k1 saturation once per term.
+func calculateBM25FScore(
+	idx *invertedIndex,
+	entry *indexedDocument,
+	queryTerms []queryTerm,
+	settings *models.SearchSettings,
+	now time.Time,
 ) float64 {
-	docLength := float64(len(doc.Tokens))
 	var score float64
 
-	for _, queryToken := range queryTokens {
-		tf := float64(termFreq[queryToken])
+	for _, qt := range queryTerms {
+		var tf float64
+		for _, field := range searchFields {
+			freq := entry.fieldFreq[field][qt.token]
+			if freq == 0 {
+				continue
+			}
+			b := settings.FieldB[field]
+			norm := 1 - b
+			if avg := idx.avgFieldLength(field); avg > 0 {
+				norm += b * float64(len(entry.fields[field])) / avg
+			}
+			tf += settings.FieldWeights[field] * float64(freq) / norm
+		}
 		if tf == 0 {
 			continue
 		}
 
-		df := float64(docFrequencies[queryToken])
-		if df == 0 {
-			df = 1
-		}
-
-		idf := math.Log((float64(docCount)-df+0.5)/(df+0.5) + 1)
-		tfComponent := (tf * (bm25K1 + 1)) / (tf + bm25K1*(1-bm25B+bm25B*(docLength/avgDocLength)))
-		score += idf * tfComponent
+		score += qt.weight * idx.idf[qt.token] * tf * (settings.K1 + 1) / (tf + settings.K1)
 	}
 
-	titleTokens := util.Tokenize(doc.Title)
-	for _, qt := range queryTokens {
-		for _, tt := range titleTokens {
-			if tt == qt {
-				score *= 1.5
-				break
-			}
-		}
+	if boost, ok := settings.TypeBoosts[entry.doc.Type]; ok {
+		score *= boost
 	}
-
-	for _, qt := range queryTokens {
-		for _, skill := range doc.Skills {
-			if strings.Contains(strings.ToLower(skill), qt) {
-				score *= 1.3
-				break
-			}
-		}
-	}
-
-	return score
+	return score * recencyBoost(settings, entry.doc.UpdatedAt, now)
 }
 
 func InvalidateSearchCache() {
 	cacheMutex.Lock()
-	cachedIndex = nil
+	searchIdx = nil
 	cacheTimestamp = time.Time{}
 	cacheMutex.Unlock()
 }
 
-func getDocumentIndex() ([]models.SearchDocument, error) {
+func upsertSearchDocument(doc models.SearchDocument) {
+	cacheMutex.Lock()
+	if searchIdx != nil {
+		searchIdx.upsert(doc)
+	}
+	cacheMutex.Unlock()
+}
+
+func removeSearchDocument(id string) {
+	cacheMutex.Lock()
+	if searchIdx != nil {
+		searchIdx.remove(id)
+	}
+	cacheMutex.Unlock()
+}
+
+// acquireSearchIndex returns the current index with the read lock held.
+// Callers must invoke the returned release func once they are done scoring.
+func acquireSearchIndex() (*invertedIndex, func(), error) {
 	cacheMutex.RLock()
-	if cachedIndex != nil && time.Since(cacheTimestamp) < indexCacheTTL {
-		result := make([]models.SearchDocument, len(cachedIndex))
-		copy(result, cachedIndex)
-		cacheMutex.RUnlock()
-		return result, nil
+	if searchIdx != nil && time.Since(cacheTimestamp) < indexCacheTTL {
+		return searchIdx, cacheMutex.RUnlock, nil
 	}
 	cacheMutex.RUnlock()
 
+	idx, err := rebuildSearchIndex()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cacheMutex.RLock()
+	if searchIdx != nil {
+		idx = searchIdx
+	}
+	return idx, cacheMutex.RUnlock, nil
+}
+
+// rebuildSearchIndex loads every document into a fresh index and swaps it in
+// whole, so searches keep using the previous index until the new one is ready.
+func rebuildSearchIndex() (*invertedIndex, error) {
+	if err := LoadSearchSettings(); err != nil {
+		slog.Warn("Failed to reload search settings, keeping previous ones", "error", err)
+	}
+	if err := LoadSynonyms(); err != nil {
+		slog.Warn("Failed to reload synonyms, keeping previous ones", "error", err)
+	}
+
 	documents, err := buildDocumentIndex()
 	if err != nil {
 		return nil, err
 	}
+	idx := newInvertedIndex(documents)
 
 	cacheMutex.Lock()
-	cachedIndex = make([]models.SearchDocument, len(documents))
-	copy(cachedIndex, documents)
+	searchIdx = idx
 	cacheTimestamp = time.Now()
 	cacheMutex.Unlock()
-
-	return documents, nil
+	return idx, nil
 }
 
 func buildDocumentIndex() ([]models.SearchDocument, error) {
 	var (
 		wg        sync.WaitGroup
 		mu        sync.Mutex
+		firstErr  error
 		documents = make([]models.SearchDocument, 0, 100)
 	)
 
+	collect := func(localDocs []models.SearchDocument, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return
+		}
+		documents = append(documents, localDocs...)
+	}
+
 	wg.Add(4)
 
 	go func() {
 		defer wg.Done()
 		var projects []models.Project
 		if err := mgm.Coll(&models.Project{}).SimpleFind(&projects, bson.M{}); err != nil {
+			collect(nil, err)
 			return
 		}
 
 		localDocs := make([]models.SearchDocument, 0, len(projects))
-
 		for i := range projects {
-			p := &projects[i]
-			localDocs = append(localDocs, models.SearchDocument{
-				ID:          p.ID.Hex(),
-				Type:        "project",
-				Title:       p.ProjectName,
-				Subtitle:    p.SmallDescription,
-				Description: p.Description,
-				Skills:      p.Skills,
-				Tokens:      p.Tokens,
-				URL:         "/projects/" + p.ID.Hex(),
-			})
+			localDocs = append(localDocs, projectSearchDocument(&projects[i]))
 		}
-
-		mu.Lock()
-		documents = append(documents, localDocs...)
-		mu.Unlock()
+		collect(localDocs, nil)
 	}()
 
 	go func() {
 		defer wg.Done()
 		var experiences []models.Experience
 		if err := mgm.Coll(&models.Experience{}).SimpleFind(&experiences, bson.M{}); err != nil {
+			collect(nil, err)
 			return
 		}
 
 		localDocs := make([]models.SearchDocument, 0, len(experiences))
-
 		for i := range experiences {
-			e := &experiences[i]
-			subtitle := ""
-			if len(e.ExperienceTimeline) > 0 {
-				subtitle = e.ExperienceTimeline[0].Position
-			}
-
-			localDocs = append(localDocs, models.SearchDocument{
-				ID:          e.ID.Hex(),
-				Type:        "experience",
-				Title:       e.CompanyName,
-				Subtitle:    subtitle,
-				Description: e.Description,
-				Skills:      e.Technologies,
-				Tokens:      e.Tokens,
-				URL:         "/experiences/" + e.ID.Hex(),
-			})
+			localDocs = append(localDocs, experienceSearchDocument(&experiences[i]))
 		}
-
-		mu.Lock()
-		documents = append(documents, localDocs...)
-		mu.Unlock()
+		collect(localDocs, nil)
 	}()
 
 	go func() {
 		defer wg.Done()
 		var certifications []models.CertificationOrAchievements
 		if err := mgm.Coll(&models.CertificationOrAchievements{}).SimpleFind(&certifications, bson.M{}); err != nil {
+			collect(nil, err)
 			return
 		}
 
 		localDocs := make([]models.SearchDocument, 0, len(certifications))
-
 		for i := range certifications {
-			c := &certifications[i]
-			localDocs = append(localDocs, models.SearchDocument{
-				ID:          c.ID.Hex(),
-				Type:        "certificate",
-				Title:       c.Title,
-				Subtitle:    c.Issuer,
-				Description: c.Description,
-				Skills:      c.Skills,
-				Tokens:      c.Tokens,
-				URL:         "/certificates/" + c.ID.Hex(),
-			})
+			localDocs = append(localDocs, certificationSearchDocument(&certifications[i]))
 		}
-
-		mu.Lock()
-		documents = append(documents, localDocs...)
-		mu.Unlock()
+		collect(localDocs, nil)
 	}()
 
 	go func() {
 		defer wg.Done()
 		var volunteers []models.VolunteerExperience
 		if err := mgm.Coll(&models.VolunteerExperience{}).SimpleFind(&volunteers, bson.M{}); err != nil {
+			collect(nil, err)
 			return
 		}
 
 		localDocs := make([]models.SearchDocument, 0, len(volunteers))
-
 		for i := range volunteers {
-			v := &volunteers[i]
-			subtitle := ""
-			if len(v.VolunteerTimeLine) > 0 {
-				subtitle = v.VolunteerTimeLine[0].PositionOfAuthority
-			}
-
-			localDocs = append(localDocs, models.SearchDocument{
-				ID:          v.ID.Hex(),
-				Type:        "volunteer",
-				Title:       v.Organisation,
-				Subtitle:    subtitle,
-				Description: v.Description,
-				Skills:      v.Technologies,
-				Tokens:      v.Tokens,
-				URL:         "/volunteer/" + v.ID.Hex(),
-			})
+			localDocs = append(localDocs, volunteerSearchDocument(&volunteers[i]))
 		}
-
-		mu.Lock()
-		documents = append(documents, localDocs...)
-		mu.Unlock()
+		collect(localDocs, nil)
 	}()
 
 	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
 	return documents, nil
 }
 
@@ -254,83 +233,135 @@ func Search(c *fiber.Ctx) error {
 	}
 
 	typeFilter := c.Query("type", "")
+	skillFilters := parseSkillFilters(c.Query("skill", ""))
+	yearFilter := strings.TrimSpace(c.Query("year", ""))
+	yearFrom, yearTo, hasYearFilter := parseYearRange(yearFilter)
+	if yearFilter != "" && !hasYearFilter {
+		return util.ResponseAPI(c, fiber.StatusBadRequest, "Invalid year filter", nil, "")
+	}
+	mode := c.Query("mode", modeKeyword)
+	if mode != modeKeyword && mode != modeSemantic && mode != modeHybrid {
+		return util.ResponseAPI(c, fiber.StatusBadRequest, "Invalid search mode", nil, "")
+	}
+	fuzzy := c.QueryBool("fuzzy", true)
 	limit := c.QueryInt("limit", 10)
 	if limit < 1 || limit > 50 {
 		limit = 10
 	}
 
-	documents, err := getDocumentIndex()
-	if err != nil {
-		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to get search index", nil, "")
-	}
-
-	if typeFilter != "" {
-		filtered := make([]models.SearchDocument, 0, len(documents)/4)
-		for i := range documents {
-			if documents[i].Type == typeFilter {
-				filtered = append(filtered, documents[i])
-			}
-		}
-		documents = filtered
-	}
-
-	if len(documents) == 0 {
+	pq := parseQuery(query)
+	if len(pq.groups) == 0 {
 		return util.ResponseAPI(c, fiber.StatusOK, "Search completed", models.SearchResponse{
 			Results:    []models.SearchResult{},
 			Query:      query,
+			Mode:       mode,
 			TotalCount: 0,
 		}, "")
 	}
 
-	// Tokenize query at runtime (document tokens are pre-stored in DB)
-	queryTokens := util.Tokenize(query)
-	if len(queryTokens) == 0 {
-		return util.ResponseAPI(c, fiber.StatusOK, "Search completed", models.SearchResponse{
-			Results:    []models.SearchResult{},
-			Query:      query,
-			TotalCount: 0,
-		}, "")
+	idx, release, err := acquireSearchIndex()
+	if err != nil {
+		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to get search index", nil, "")
+	}
+	defer release()
+
+	pq.applySynonyms(currentSynonyms())
+
+	freeWords, exactWords := pq.scoringWords()
+	queryTerms, corrections := expandQuery(idx, freeWords, fuzzy)
+	exactTerms, _ := expandQuery(idx, exactWords, false)
+	queryTerms = append(queryTerms, exactTerms...)
+
+	expansions := make(map[string][]string, len(queryTerms))
+	for _, qt := range queryTerms {
+		expansions[qt.source] = append(expansions[qt.source], qt.token)
 	}
+	queryTerms = append(queryTerms, pq.synonymTerms(queryTerms)...)
 
-	totalTokens := 0
-	for i := range documents {
-		totalTokens += len(documents[i].Tokens)
+	didYouMean := ""
+	if len(corrections) > 0 {
+		didYouMean = pq.render(corrections)
 	}
-	avgDocLength := float64(totalTokens) / float64(len(documents))
-
-	docFrequencies := make(map[string]int, len(queryTokens))
-	for _, token := range queryTokens {
-		for i := range documents {
-			for _, docToken := range documents[i].Tokens {
-				if docToken == token {
-					docFrequencies[token]++
-					break
+
+	var semanticScores map[string]float64
+	if mode != modeKeyword {
+		tokens := semanticQuery(slices.Concat(freeWords, exactWords), queryTerms)
+		semanticScores = idx.semantic.search(tokens, limit*semanticOverfetch)
+	}
+
+	candidates := make(map[string]*indexedDocument, 16)
+	if pq.needsFullScan() {
+		candidates = idx.docs
+	} else {
+		if mode != modeSemantic {
+			for _, qt := range queryTerms {
+				for id := range idx.postings[qt.token] {
+					candidates[id] = idx.docs[id]
 				}
 			}
 		}
+		for id := range semanticScores {
+			candidates[id] = idx.docs[id]
+		}
 	}
 
-	scoredDocs := make([]scoredDocument, 0, len(documents)/2)
-	termFreq := make(map[string]int, 32)
-
-	for i := range documents {
-		doc := &documents[i]
-
-		for k := range termFreq {
-			delete(termFreq, k)
+	settings := currentSearchSettings()
+	now := time.Now()
+	requireScore := !pq.hasRequired()
+	keywordScores := make(map[string]float64, len(candidates))
+	matched := make([]*indexedDocument, 0, len(candidates))
+	facets := newFacetCounter()
+	for _, entry := range candidates {
+		if !pq.accepts(entry, expansions) {
+			continue
+		}
+		if hasYearFilter && !hasYearIn(entry.doc.Years, yearFrom, yearTo) {
+			continue
 		}
-		for _, token := range doc.Tokens {
-			termFreq[token]++
+		score := calculateBM25FScore(idx, entry, queryTerms, &settings, now)
+		semanticScore := semanticScores[entry.doc.ID]
+		found := score > 0
+		switch mode {
+		case modeSemantic:
+			found = semanticScore > 0
+		case modeHybrid:
+			found = score > 0 || semanticScore > 0
+		}
+		if !found && requireScore {
+			continue
 		}
+		keywordScores[entry.doc.ID] = score
 
-		score := calculateBM25Score(doc, queryTokens, avgDocLength, len(documents), docFrequencies, termFreq)
-		if score > 0 {
-			scoredDocs = append(scoredDocs, scoredDocument{score: score, doc: doc})
+		typeOK := typeFilter == "" || entry.doc.Type == typeFilter
+		skillsOK := hasSkills(&entry.doc, skillFilters)
+		facets.add(&entry.doc, typeOK, skillsOK)
+		if typeOK && skillsOK {
+			matched = append(matched, entry)
 		}
 	}
 
+	// Keyword scores are BM25F, semantic scores cosine similarities and hybrid
+	// scores the reciprocal-rank fusion of both rankings.
+	finalScores := keywordScores
+	precision := 100.0
+	switch mode {
+	case modeSemantic:
+		finalScores = semanticScores
+	case modeHybrid:
+		finalScores = reciprocalRankFusion(keywordScores, semanticScores)
+		precision = 10000
+	}
+
+	scoredDocs := make([]scoredDocument, 0, len(matched))
+	for _, entry := range matched {
+		scoredDocs = append(scoredDocs, scoredDocument{score: finalScores[entry.doc.ID], doc: &entry.doc})
+	}
+
 	sort.Slice(scoredDocs, func(i, j int) bool {
-		return scoredDocs[i].score > scoredDocs[j].score
+		if scoredDocs[i].score != scoredDocs[j].score {
+			return scoredDocs[i].score > scoredDocs[j].score
+		}
+		return scoredDocs[i].doc.ID < scoredDocs[j].doc.ID
 	})
 
 	resultCount := limit
@@ -339,152 +370,66 @@ func Search(c *fiber.Ctx) error {
 	}
 	results := make([]models.SearchResult, 0, resultCount)
 
-	seenIDs := make(map[string]struct{}, resultCount)
+	matchTerms := make(map[string]struct{}, len(queryTerms))
+	for _, qt := range queryTerms {
+		matchTerms[qt.token] = struct{}{}
+	}
 
-	for i := 0; i < len(scoredDocs) && len(results) < resultCount; i++ {
+	for i := 0; i < resultCount; i++ {
 		sd := &scoredDocs[i]
 
-		if _, exists := seenIDs[sd.doc.ID]; exists {
-			continue
+		result := models.SearchResult{
+			ID:       sd.doc.ID,
+			Type:     sd.doc.Type,
+			Title:    sd.doc.Title,
+			Subtitle: sd.doc.Subtitle,
+			Skills:   sd.doc.Skills,
+			Score:    math.Round(sd.score*precision) / precision,
+			URL:      sd.doc.URL,
 		}
-		seenIDs[sd.doc.ID] = struct{}{}
-
-		description := sd.doc.Description
-		if len(description) > 150 {
-			description = description[:150] + "..."
-		}
-
-		results = append(results, models.SearchResult{
-			ID:          sd.doc.ID,
-			Type:        sd.doc.Type,
-			Title:       sd.doc.Title,
-			Subtitle:    sd.doc.Subtitle,
-			Description: description,
-			Skills:      sd.doc.Skills,
-			Score:       math.Round(sd.score*100) / 100,
-			URL:         sd.doc.URL,
-		})
+		highlightResult(&result, sd.doc, matchTerms)
+		results = append(results, result)
 	}
 
+	queryID := recordSearch(&pq, models.SearchFilters{
+		Skills: skillFilters,
+		Type:   typeFilter,
+		Year:   yearFilter,
+		Mode:   mode,
+		Fuzzy:  fuzzy,
+	}, len(scoredDocs))
+
 	return util.ResponseAPI(c, fiber.StatusOK, "Search completed", models.SearchResponse{
 		Results:    results,
+		Facets:     facets.result(defaultSkillFacetLimit),
 		Query:      query,
+		DidYouMean: didYouMean,
+		QueryID:    queryID,
+		Mode:       mode,
 		TotalCount: len(results),
 	}, "")
 }
 
 func GetSearchSuggestions(c *fiber.Ctx) error {
-	query := c.Query("q", "")
+	query := strings.TrimSpace(c.Query("q", ""))
 	if len(query) < 2 {
 		return util.ResponseAPI(c, fiber.StatusOK, "Suggestions", fiber.Map{
-			"suggestions": []string{},
+			"suggestions": []models.SearchSuggestion{},
 		}, "")
 	}
 
-	query = strings.ToLower(query)
-
-	var (
-		wg            sync.WaitGroup
-		mu            sync.Mutex
-		suggestionSet = make(map[string]struct{}, 32)
-	)
-
-	wg.Add(3)
-
-	go func() {
-		defer wg.Done()
-		var projects []models.Project
-		if err := mgm.Coll(&models.Project{}).SimpleFind(&projects, bson.M{}); err != nil {
-			return
-		}
-
-		localSuggestions := make([]string, 0, 16)
-		for i := range projects {
-			p := &projects[i]
-			for _, skill := range p.Skills {
-				if strings.Contains(strings.ToLower(skill), query) {
-					localSuggestions = append(localSuggestions, skill)
-				}
-			}
-			if strings.Contains(strings.ToLower(p.ProjectName), query) {
-				localSuggestions = append(localSuggestions, p.ProjectName)
-			}
-		}
-
-		mu.Lock()
-		for _, s := range localSuggestions {
-			suggestionSet[s] = struct{}{}
-		}
-		mu.Unlock()
-	}()
-
-	go func() {
-		defer wg.Done()
-		var experiences []models.Experience
-		if err := mgm.Coll(&models.Experience{}).SimpleFind(&experiences, bson.M{}); err != nil {
-			return
-		}
-
-		localSuggestions := make([]string, 0, 16)
-		for i := range experiences {
-			e := &experiences[i]
-			for _, tech := range e.Technologies {
-				if strings.Contains(strings.ToLower(tech), query) {
-					localSuggestions = append(localSuggestions, tech)
-				}
-			}
-			if strings.Contains(strings.ToLower(e.CompanyName), query) {
-				localSuggestions = append(localSuggestions, e.CompanyName)
-			}
-		}
-
-		mu.Lock()
-		for _, s := range localSuggestions {
-			suggestionSet[s] = struct{}{}
-		}
-		mu.Unlock()
-	}()
-
-	go func() {
-		defer wg.Done()
-		var certifications []models.CertificationOrAchievements
-		if err := mgm.Coll(&models.CertificationOrAchievements{}).SimpleFind(&certifications, bson.M{}); err != nil {
-			return
-		}
-
-		localSuggestions := make([]string, 0, 16)
-		for i := range certifications {
-			cert := &certifications[i]
-			for _, skill := range cert.Skills {
-				if strings.Contains(strings.ToLower(skill), query) {
-					localSuggestions = append(localSuggestions, skill)
-				}
-			}
-			if strings.Contains(strings.ToLower(cert.Title), query) {
-				localSuggestions = append(localSuggestions, cert.Title)
-			}
-		}
-
-		mu.Lock()
-		for _, s := range localSuggestions {
-			suggestionSet[s] = struct{}{}
-		}
-		mu.Unlock()
-	}()
-
-	wg.Wait()
-
-	suggestions := make([]string, 0, len(suggestionSet))
-	for s := range suggestionSet {
-		suggestions = append(suggestions, s)
+	idx, release, err := acquireSearchIndex()
+	if err != nil {
+		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to get search index", nil, "")
 	}
+	defer release()
 
-	sort.Strings(suggestions)
-	if len(suggestions) > 8 {
-		suggestions = suggestions[:8]
+	suggestions := idx.suggestions.complete(query, maxSuggestions)
+	for _, alt := range currentSynonyms().rawAlternatives(query) {
+		suggestions = append(suggestions, idx.suggestions.complete(alt, maxSuggestions)...)
 	}
 
 	return util.ResponseAPI(c, fiber.StatusOK, "Suggestions", fiber.Map{
-		"suggestions": suggestions,
+		"suggestions": rankSuggestions(suggestions, maxSuggestions),
 	}, "")
 }
@@ -0,0 +1,318 @@
+package controller
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/MishraShardendu22/models"
+	"github.com/MishraShardendu22/util"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kamva/mgm/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	analyticsQueueSize    = 256
+	defaultAnalyticsDays  = 30
+	maxAnalyticsDays      = 365
+	defaultAnalyticsLimit = 20
+
+	// How often dropped records are reported, and how long the queue may take
+	// to empty on shutdown.
+	analyticsDropReportInterval = time.Minute
+	analyticsFlushTimeout       = 10 * time.Second
+)
+
+// Searches and clicks are written by a single background worker so the
+// request path never waits on Mongo. When the queue is full records are
+// dropped, since analytics are best effort, and counted in analyticsDropped
+// until the worker logs them.
+var (
+	analyticsQueue   = make(chan mgm.Model, analyticsQueueSize)
+	analyticsDropped atomic.Int64
+)
+
+// StartSearchAnalytics stores queued analytics records until ctx is done,
+// then stores what is still queued and returns.
+func StartSearchAnalytics(ctx context.Context, logger *slog.Logger) {
+	if err := ensureAnalyticsRetention(ctx); err != nil {
+		logger.Warn("Failed to create search analytics TTL indexes", "error", err)
+	}
+
+	ticker := time.NewTicker(analyticsDropReportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushAnalytics(logger)
+			return
+		case <-ticker.C:
+			reportDroppedAnalytics(logger)
+		case record := <-analyticsQueue:
+			storeAnalytics(ctx, logger, record)
+		}
+	}
+}
+
+// ensureAnalyticsRetention lets Mongo delete searches and clicks once they
+// are older than the longest report window, maxAnalyticsDays.
+func ensureAnalyticsRetention(ctx context.Context) error {
+	ttl := int32(maxAnalyticsDays * 24 * time.Hour / time.Second)
+	for _, model := range []mgm.Model{&models.SearchQueryLog{}, &models.SearchClick{}} {
+		_, err := mgm.Coll(model).Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(ttl),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flushAnalytics stores the records left in the queue, giving up after
+// analyticsFlushTimeout.
+func flushAnalytics(logger *slog.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), analyticsFlushTimeout)
+	defer cancel()
+
+	defer reportDroppedAnalytics(logger)
+	for ctx.Err() == nil {
+		select {
+		case record := <-analyticsQueue:
+			storeAnalytics(ctx, logger, record)
+		default:
+			return
+		}
+	}
+	logger.Warn("Search analytics left unsaved at shutdown", "records", len(analyticsQueue))
+}
+
+func storeAnalytics(ctx context.Context, logger *slog.Logger, record mgm.Model) {
+	if err := mgm.Coll(record).CreateWithCtx(ctx, record); err != nil {
+		logger.Warn("Failed to store search analytics", "error", err)
+	}
+}
+
+func reportDroppedAnalytics(logger *slog.Logger) {
+	if n := analyticsDropped.Swap(0); n > 0 {
+		logger.Warn("Dropped search analytics, queue full", "records", n, "queue_size", analyticsQueueSize)
+	}
+}
+
+func enqueueAnalytics(record mgm.Model) {
+	select {
+	case analyticsQueue <- record:
+	default:
+		analyticsDropped.Add(1)
+	}
+}
+
+// normalizedTokens reduces the query to analyzed tokens, so "Kubernetes" and
+// "kubernetes" are counted as one query. Qualifiers keep their field prefix.
+func (pq *parsedQuery) normalizedTokens() []string {
+	tokens := make([]string, 0, 8)
+	add := func(c *queryClause) {
+		prefix := ""
+		if c.negated {
+			prefix = "-"
+		}
+		switch {
+		case c.field == "type" || c.field == "year":
+			tokens = append(tokens, prefix+c.field+":"+c.value)
+		case c.field != "":
+			tokens = append(tokens, prefix+c.field+":"+strings.Join(c.words, " "))
+		default:
+			for _, word := range c.words {
+				tokens = append(tokens, prefix+word)
+			}
+		}
+	}
+
+	for _, g := range pq.groups {
+		for i := range g {
+			add(&g[i])
+		}
+	}
+	for i := range pq.excluded {
+		add(&pq.excluded[i])
+	}
+	return tokens
+}
+
+// recordSearch queues a query log entry and returns its ID, which the client
+// sends back when a result is clicked.
+func recordSearch(pq *parsedQuery, filters models.SearchFilters, resultCount int) string {
+	tokens := pq.normalizedTokens()
+	entry := &models.SearchQueryLog{
+		Tokens:      tokens,
+		Filters:     filters,
+		Normalized:  strings.Join(tokens, " "),
+		ResultCount: resultCount,
+	}
+	entry.SetID(primitive.NewObjectID())
+	enqueueAnalytics(entry)
+	return entry.ID.Hex()
+}
+
+func RecordSearchClick(c *fiber.Ctx) error {
+	var input struct {
+		QueryID    string `json:"query_id"`
+		ResultID   string `json:"result_id"`
+		ResultType string `json:"result_type"`
+		Position   int    `json:"position"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return util.ResponseAPI(c, fiber.StatusBadRequest, "Invalid request body", nil, "")
+	}
+
+	queryID, err := primitive.ObjectIDFromHex(input.QueryID)
+	if err != nil {
+		return util.ResponseAPI(c, fiber.StatusBadRequest, "Invalid query ID", nil, "")
+	}
+	if _, err := primitive.ObjectIDFromHex(input.ResultID); err != nil {
+		return util.ResponseAPI(c, fiber.StatusBadRequest, "Invalid result ID", nil, "")
+	}
+	if input.ResultType != "" && !slices.Contains(searchTypes, input.ResultType) {
+		return util.ResponseAPI(c, fiber.StatusBadRequest, "Invalid result type", nil, "")
+	}
+
+	enqueueAnalytics(&models.SearchClick{
+		QueryID:    queryID,
+		ResultID:   input.ResultID,
+		ResultType: input.ResultType,
+		Position:   max(input.Position, 0),
+	})
+	return util.ResponseAPI(c, fiber.StatusAccepted, "Click recorded", nil, "")
+}
+
+// analyticsWindow reads the days and limit query parameters shared by the
+// analytics reports.
+func analyticsWindow(c *fiber.Ctx) (since time.Time, days int, limit int) {
+	days = c.QueryInt("days", defaultAnalyticsDays)
+	if days < 1 || days > maxAnalyticsDays {
+		days = defaultAnalyticsDays
+	}
+	limit = c.QueryInt("limit", defaultAnalyticsLimit)
+	if limit < 1 || limit > 100 {
+		limit = defaultAnalyticsLimit
+	}
+	return time.Now().AddDate(0, 0, -days), days, limit
+}
+
+func aggregateQueryStats(ctx context.Context, match bson.M, limit int) ([]models.SearchQueryStat, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":           "$normalized",
+			"count":         bson.M{"$sum": 1},
+			"avg_results":   bson.M{"$avg": "$result_count"},
+			"last_searched": bson.M{"$max": "$created_at"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
+
+	cursor, err := mgm.Coll(&models.SearchQueryLog{}).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	stats := []models.SearchQueryStat{}
+	if err := cursor.All(ctx, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func GetTopSearchQueries(c *fiber.Ctx) error {
+	since, days, limit := analyticsWindow(c)
+	stats, err := aggregateQueryStats(c.Context(), bson.M{
+		"created_at": bson.M{"$gte": since},
+		"normalized": bson.M{"$ne": ""},
+	}, limit)
+	if err != nil {
+		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to aggregate search queries", nil, "")
+	}
+
+	return util.ResponseAPI(c, fiber.StatusOK, "Top search queries", fiber.Map{
+		"queries": stats,
+		"days":    days,
+	}, "")
+}
+
+func GetZeroResultQueries(c *fiber.Ctx) error {
+	since, days, limit := analyticsWindow(c)
+	stats, err := aggregateQueryStats(c.Context(), bson.M{
+		"created_at":   bson.M{"$gte": since},
+		"normalized":   bson.M{"$ne": ""},
+		"result_count": 0,
+	}, limit)
+	if err != nil {
+		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to aggregate search queries", nil, "")
+	}
+
+	return util.ResponseAPI(c, fiber.StatusOK, "Zero-result search queries", fiber.Map{
+		"queries": stats,
+		"days":    days,
+	}, "")
+}
+
+func GetSearchClickThrough(c *fiber.Ctx) error {
+	since, days, limit := analyticsWindow(c)
+	ctx := c.Context()
+	window := bson.M{"created_at": bson.M{"$gte": since}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: window}},
+		{{Key: "$group", Value: bson.M{
+			"_id":          "$result_id",
+			"result_type":  bson.M{"$first": "$result_type"},
+			"clicks":       bson.M{"$sum": 1},
+			"queries":      bson.M{"$addToSet": "$query_id"},
+			"avg_position": bson.M{"$avg": "$position"},
+		}}},
+		{{Key: "$set", Value: bson.M{"queries": bson.M{"$size": "$queries"}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "clicks", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
+
+	clickColl := mgm.Coll(&models.SearchClick{})
+	cursor, err := clickColl.Aggregate(ctx, pipeline)
+	if err != nil {
+		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to aggregate search clicks", nil, "")
+	}
+	results := []models.SearchClickStat{}
+	if err := cursor.All(ctx, &results); err != nil {
+		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to decode search clicks", nil, "")
+	}
+
+	searches, err := mgm.Coll(&models.SearchQueryLog{}).CountDocuments(ctx, window)
+	if err != nil {
+		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to count searches", nil, "")
+	}
+	clickedQueries, err := clickColl.Distinct(ctx, "query_id", window)
+	if err != nil {
+		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to count clicked searches", nil, "")
+	}
+
+	rate := 0.0
+	if searches > 0 {
+		rate = float64(len(clickedQueries)) / float64(searches)
+	}
+
+	return util.ResponseAPI(c, fiber.StatusOK, "Search click-through", fiber.Map{
+		"results":            results,
+		"searches":           searches,
+		"clicked_searches":   len(clickedQueries),
+		"click_through_rate": rate,
+		"days":               days,
+	}, "")
+}
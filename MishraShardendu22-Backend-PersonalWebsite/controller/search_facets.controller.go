@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/MishraShardendu22/models"
+)
+
+const defaultSkillFacetLimit = 10
+
+var searchTypes = []string{"project", "experience", "certificate", "volunteer"}
+
+// facetCounter tallies facet values over the matching documents. Each facet
+// ignores its own filter, so a selected chip still shows counts for the
+// alternatives next to it.
+type facetCounter struct {
+	types      map[string]int
+	skills     map[string]int
+	skillNames map[string]string
+}
+
+func newFacetCounter() *facetCounter {
+	return &facetCounter{
+		types:      make(map[string]int, len(searchTypes)),
+		skills:     make(map[string]int, 32),
+		skillNames: make(map[string]string, 32),
+	}
+}
+
+func (f *facetCounter) add(doc *models.SearchDocument, typeOK, skillsOK bool) {
+	if skillsOK {
+		f.types[doc.Type]++
+	}
+	if !typeOK {
+		return
+	}
+	for _, skill := range uniqueFold(doc.Skills) {
+		key := normalizeSkill(skill)
+		if _, ok := f.skillNames[key]; !ok {
+			f.skillNames[key] = strings.TrimSpace(skill)
+		}
+		f.skills[key]++
+	}
+}
+
+func (f *facetCounter) result(skillLimit int) *models.SearchFacets {
+	facets := &models.SearchFacets{
+		Types:  make([]models.FacetCount, 0, len(searchTypes)),
+		Skills: make([]models.FacetCount, 0, min(skillLimit, len(f.skills))),
+	}
+	for _, t := range searchTypes {
+		facets.Types = append(facets.Types, models.FacetCount{Value: t, Count: f.types[t]})
+	}
+
+	keys := make([]string, 0, len(f.skills))
+	for key := range f.skills {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if f.skills[keys[i]] != f.skills[keys[j]] {
+			return f.skills[keys[i]] > f.skills[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > skillLimit {
+		keys = keys[:skillLimit]
+	}
+	for _, key := range keys {
+		facets.Skills = append(facets.Skills, models.FacetCount{Value: f.skillNames[key], Count: f.skills[key]})
+	}
+	return facets
+}
+
+func normalizeSkill(skill string) string {
+	return strings.ToLower(strings.TrimSpace(skill))
+}
+
+// parseSkillFilters reads the comma separated skill facet selection.
+func parseSkillFilters(raw string) []string {
+	var filters []string
+	for _, part := range strings.Split(raw, ",") {
+		if skill := normalizeSkill(part); skill != "" {
+			filters = append(filters, skill)
+		}
+	}
+	return filters
+}
+
+// hasSkills reports whether the document lists every selected skill.
+func hasSkills(doc *models.SearchDocument, filters []string) bool {
+	for _, want := range filters {
+		found := false
+		for _, skill := range doc.Skills {
+			if normalizeSkill(skill) == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
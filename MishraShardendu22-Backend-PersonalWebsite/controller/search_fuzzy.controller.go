@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/MishraShardendu22/util"
+)
+
+const (
+	fuzzyMinTermLength = 4
+	fuzzyMaxExpansions = 3
+	fuzzyPrefixWeight  = 0.8
+)
+
+// queryTerm is a vocabulary term the query is scored against. Fuzzy
+// expansions keep a pointer back to the token the visitor actually typed.
+type queryTerm struct {
+	token  string
+	source string
+	weight float64
+}
+
+type fuzzyMatch struct {
+	term     string
+	distance int
+	prefix   bool
+}
+
+func (m fuzzyMatch) weight() float64 {
+	if m.prefix {
+		return fuzzyPrefixWeight
+	}
+	return 1 / float64(1+m.distance)
+}
+
+func maxEditsFor(token string) int {
+	n := utf8.RuneCountInString(token)
+	switch {
+	case n < fuzzyMinTermLength:
+		return 0
+	case n <= 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// fuzzyMatches looks up vocabulary terms sharing a trigram with token and
+// keeps the ones within edit distance or extending it as a prefix.
+func (idx *invertedIndex) fuzzyMatches(token string) []fuzzyMatch {
+	maxEdits := maxEditsFor(token)
+	if maxEdits == 0 {
+		return nil
+	}
+
+	seen := make(map[string]struct{}, 16)
+	matches := make([]fuzzyMatch, 0, 4)
+	for _, gram := range util.Trigrams(token) {
+		for term := range idx.trigrams[gram] {
+			if _, ok := seen[term]; ok {
+				continue
+			}
+			seen[term] = struct{}{}
+
+			if strings.HasPrefix(term, token) {
+				matches = append(matches, fuzzyMatch{term: term, prefix: true})
+				continue
+			}
+			if d := util.EditDistance(token, term, maxEdits); d <= maxEdits {
+				matches = append(matches, fuzzyMatch{term: term, distance: d})
+			}
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		wi, wj := matches[i].weight(), matches[j].weight()
+		if wi != wj {
+			return wi > wj
+		}
+		dfi, dfj := len(idx.postings[matches[i].term]), len(idx.postings[matches[j].term])
+		if dfi != dfj {
+			return dfi > dfj
+		}
+		return matches[i].term < matches[j].term
+	})
+
+	if len(matches) > fuzzyMaxExpansions {
+		matches = matches[:fuzzyMaxExpansions]
+	}
+	return matches
+}
+
+// expandQuery maps query tokens onto the index vocabulary. Tokens the index
+// already knows are kept as-is; unknown ones are replaced by their closest
+// fuzzy matches when fuzzy is enabled. corrections records the best match
+// for every token that was rewritten.
+func expandQuery(idx *invertedIndex, tokens []string, fuzzy bool) ([]queryTerm, map[string]string) {
+	terms := make([]queryTerm, 0, len(tokens))
+	corrections := make(map[string]string)
+
+	for _, token := range tokens {
+		if _, ok := idx.postings[token]; ok {
+			terms = append(terms, queryTerm{token: token, source: token, weight: 1})
+			continue
+		}
+		if !fuzzy {
+			continue
+		}
+
+		matches := idx.fuzzyMatches(token)
+		if len(matches) == 0 {
+			continue
+		}
+		for _, m := range matches {
+			terms = append(terms, queryTerm{token: m.term, source: token, weight: m.weight()})
+		}
+		corrections[token] = matches[0].term
+	}
+
+	return terms, corrections
+}
@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/MishraShardendu22/models"
+	"github.com/MishraShardendu22/util"
+)
+
+const (
+	snippetLength    = 160
+	snippetSnapRange = 20
+	snippetEllipsis  = "..."
+)
+
+// matchSpans returns the rune ranges of text whose tokens are query terms.
+func matchSpans(text string, terms map[string]struct{}) []models.HighlightSpan {
+	var spans []models.HighlightSpan
+	for _, span := range util.TokenSpans(text) {
+		if _, ok := terms[span.Token]; ok {
+			spans = append(spans, models.HighlightSpan{Start: span.Start, End: span.End})
+		}
+	}
+	return spans
+}
+
+// buildSnippet cuts a window of at most snippetLength runes out of text,
+// centred on the densest cluster of matches, and returns it with the match
+// offsets rebased onto the snippet.
+func buildSnippet(text string, terms map[string]struct{}) (string, []models.HighlightSpan) {
+	runes := []rune(text)
+	spans := matchSpans(text, terms)
+	if len(runes) <= snippetLength {
+		return text, spans
+	}
+
+	start := 0
+	if len(spans) > 0 {
+		bestFirst, bestLast := 0, 0
+		for i := range spans {
+			last := i
+			for last+1 < len(spans) && spans[last+1].End-spans[i].Start <= snippetLength {
+				last++
+			}
+			if last-i > bestLast-bestFirst {
+				bestFirst, bestLast = i, last
+			}
+		}
+
+		clusterStart, clusterEnd := spans[bestFirst].Start, spans[bestLast].End
+		start = clusterStart - (snippetLength-(clusterEnd-clusterStart))/2
+		start = max(0, min(start, len(runes)-snippetLength))
+
+		// Prefer starting on a word, as long as the first match stays in view.
+		if start > 0 && !unicode.IsSpace(runes[start-1]) {
+			for i := start; i < min(start+snippetSnapRange, clusterStart); i++ {
+				if unicode.IsSpace(runes[i]) {
+					start = i + 1
+					break
+				}
+			}
+		}
+	}
+
+	end := min(start+snippetLength, len(runes))
+	if end < len(runes) && !unicode.IsSpace(runes[end]) {
+		floor := start
+		for _, span := range spans {
+			if span.End <= end {
+				floor = max(floor, span.End)
+			}
+		}
+		for i := end - 1; i > max(floor, end-snippetSnapRange); i-- {
+			if unicode.IsSpace(runes[i]) {
+				end = i
+				break
+			}
+		}
+	}
+
+	body := string(runes[start:end])
+	trimmedLeft := len([]rune(body)) - len([]rune(strings.TrimLeftFunc(body, unicode.IsSpace)))
+	body = strings.TrimSpace(body)
+
+	var b strings.Builder
+	offset := -start - trimmedLeft
+	if start > 0 {
+		b.WriteString(snippetEllipsis)
+		offset += len(snippetEllipsis)
+	}
+	b.WriteString(body)
+	if end < len(runes) {
+		b.WriteString(snippetEllipsis)
+	}
+
+	bodyEnd := start + trimmedLeft + len([]rune(body))
+	shifted := make([]models.HighlightSpan, 0, len(spans))
+	for _, span := range spans {
+		if span.Start >= start+trimmedLeft && span.End <= bodyEnd {
+			shifted = append(shifted, models.HighlightSpan{Start: span.Start + offset, End: span.End + offset})
+		}
+	}
+
+	return b.String(), shifted
+}
+
+// utf16Spans converts rune offsets into text to UTF-16 code units, which is
+// how JavaScript indexes strings. Runes outside the BMP take two units.
+func utf16Spans(text string, spans []models.HighlightSpan) []models.HighlightSpan {
+	if len(spans) == 0 {
+		return spans
+	}
+	units := make([]int, 0, len(text)+1) // rune index -> UTF-16 offset
+	n := 0
+	for _, r := range text {
+		units = append(units, n)
+		n += utf16.RuneLen(r)
+	}
+	units = append(units, n)
+
+	for i := range spans {
+		spans[i].Start = units[spans[i].Start]
+		spans[i].End = units[spans[i].End]
+	}
+	return spans
+}
+
+// highlightResult fills the snippet and highlight offsets of a search hit.
+// Offsets are worked out in runes and sent in UTF-16 code units.
+func highlightResult(result *models.SearchResult, doc *models.SearchDocument, terms map[string]struct{}) {
+	snippet, descriptionSpans := buildSnippet(doc.Description, terms)
+	result.Description = snippet
+
+	highlights := models.SearchHighlights{
+		Title:       utf16Spans(doc.Title, matchSpans(doc.Title, terms)),
+		Subtitle:    utf16Spans(doc.Subtitle, matchSpans(doc.Subtitle, terms)),
+		Description: utf16Spans(snippet, descriptionSpans),
+	}
+	if len(highlights.Title)+len(highlights.Subtitle)+len(highlights.Description) > 0 {
+		result.Highlights = &highlights
+	}
+}
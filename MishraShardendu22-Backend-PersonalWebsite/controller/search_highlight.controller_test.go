@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/MishraShardendu22/models"
+	"github.com/MishraShardendu22/util"
+)
+
+// sliceUTF16 cuts s the way JavaScript's String.prototype.slice does.
+func sliceUTF16(s string, span models.HighlightSpan) string {
+	units := utf16.Encode([]rune(s))
+	return string(utf16.Decode(units[span.Start:span.End]))
+}
+
+func TestHighlightOffsetsAreUTF16(t *testing.T) {
+	doc := &models.SearchDocument{
+		Title:       "🚀 Rocket launcher in Go",
+		Description: "Ships 🚀🚀 fast: the launcher is written in Go.",
+	}
+	terms := map[string]struct{}{}
+	for _, token := range util.Tokenize("launcher") {
+		terms[token] = struct{}{}
+	}
+
+	var result models.SearchResult
+	highlightResult(&result, doc, terms)
+	if result.Highlights == nil || len(result.Highlights.Title) != 1 || len(result.Highlights.Description) != 1 {
+		t.Fatalf("unexpected highlights %+v", result.Highlights)
+	}
+	if got := sliceUTF16(doc.Title, result.Highlights.Title[0]); got != "launcher" {
+		t.Errorf("title span covers %q", got)
+	}
+	if got := sliceUTF16(result.Description, result.Highlights.Description[0]); got != "launcher" {
+		t.Errorf("description span covers %q", got)
+	}
+	if !strings.Contains(result.Description, "🚀") {
+		t.Errorf("snippet %q lost the emoji", result.Description)
+	}
+}
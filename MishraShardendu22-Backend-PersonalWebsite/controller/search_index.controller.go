@@ -0,0 +1,318 @@
+package controller
+
+import (
+	"math"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/MishraShardendu22/models"
+	"github.com/MishraShardendu22/util"
+)
+
+// Fields scored separately by BM25F.
+const (
+	fieldTitle       = "title"
+	fieldSubtitle    = "subtitle" // project summary
+	fieldDescription = "description"
+	fieldSkills      = "skills"
+	fieldPositions   = "positions"
+	fieldIssuer      = "issuer"
+	fieldLinks       = "links"
+)
+
+var searchFields = []string{
+	fieldTitle, fieldSubtitle, fieldDescription, fieldSkills,
+	fieldPositions, fieldIssuer, fieldLinks,
+}
+
+type indexedDocument struct {
+	doc       models.SearchDocument
+	termFreq  map[string]int            // term -> frequency summed over fields
+	fields    map[string][]string       // field -> analyzed tokens in order
+	fieldFreq map[string]map[string]int // field -> term -> frequency
+}
+
+// invertedIndex maps every term to the documents containing it so a query
+// only touches the posting lists of its own terms.
+type invertedIndex struct {
+	docs        map[string]*indexedDocument
+	postings    map[string]map[string]int // term -> doc ID -> term frequency
+	idf         map[string]float64
+	trigrams    map[string]map[string]struct{} // trigram -> vocabulary terms
+	suggestions *suggestionTrie
+	fieldTotals map[string]int // field -> summed length over all documents
+	semantic    *semanticIndex
+}
+
+func newInvertedIndex(documents []models.SearchDocument) *invertedIndex {
+	idx := &invertedIndex{
+		docs:        make(map[string]*indexedDocument, len(documents)),
+		postings:    make(map[string]map[string]int, 256),
+		idf:         make(map[string]float64, 256),
+		trigrams:    make(map[string]map[string]struct{}, 512),
+		suggestions: newSuggestionTrie(),
+		fieldTotals: make(map[string]int, len(searchFields)),
+	}
+	for i := range documents {
+		idx.insert(documents[i])
+	}
+	idx.recomputeIDF()
+	idx.semantic = newSemanticIndex(idx.docs)
+	return idx
+}
+
+func documentFields(doc *models.SearchDocument) map[string][]string {
+	return map[string][]string{
+		fieldTitle:       util.Tokenize(doc.Title),
+		fieldSubtitle:    util.Tokenize(doc.Summary),
+		fieldDescription: util.Tokenize(doc.Description),
+		fieldSkills:      util.Tokenize(strings.Join(doc.Skills, " ")),
+		fieldPositions:   util.Tokenize(strings.Join(doc.Positions, " ")),
+		fieldIssuer:      util.Tokenize(doc.Issuer),
+		fieldLinks:       util.Tokenize(hostWords(doc.Hosts)),
+	}
+}
+
+// hostWords drops the top-level domain of each host, which would otherwise
+// match every link: "myapp.vercel.app" indexes as "myapp vercel".
+func hostWords(hosts []string) string {
+	words := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		words = append(words, strings.TrimSuffix(host, path.Ext(host)))
+	}
+	return strings.Join(words, " ")
+}
+
+func projectHosts(p *models.Project) []string {
+	hosts := make([]string, 0, 3)
+	for _, link := range []string{p.ProjectRepository, p.ProjectLiveLink, p.ProjectVideo} {
+		if host := util.URLHostname(link); host != "" && !slices.Contains(hosts, host) {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// uniqueYears sorts the years covered by several date ranges and drops
+// overlaps between them.
+func uniqueYears(years []int) []int {
+	slices.Sort(years)
+	return slices.Compact(years)
+}
+
+func (idx *invertedIndex) insert(doc models.SearchDocument) {
+	entry := &indexedDocument{
+		doc:       doc,
+		termFreq:  make(map[string]int, len(doc.Tokens)),
+		fields:    documentFields(&doc),
+		fieldFreq: make(map[string]map[string]int, len(searchFields)),
+	}
+	for field, tokens := range entry.fields {
+		freq := make(map[string]int, len(tokens))
+		for _, token := range tokens {
+			freq[token]++
+			entry.termFreq[token]++
+		}
+		entry.fieldFreq[field] = freq
+		idx.fieldTotals[field] += len(tokens)
+	}
+
+	for term, tf := range entry.termFreq {
+		posting, ok := idx.postings[term]
+		if !ok {
+			posting = make(map[string]int, 4)
+			idx.postings[term] = posting
+			idx.addTrigrams(term)
+		}
+		posting[doc.ID] = tf
+	}
+
+	idx.docs[doc.ID] = entry
+	idx.suggestions.addDocument(&entry.doc)
+}
+
+func (idx *invertedIndex) delete(id string) bool {
+	entry, ok := idx.docs[id]
+	if !ok {
+		return false
+	}
+
+	for term := range entry.termFreq {
+		posting := idx.postings[term]
+		delete(posting, id)
+		if len(posting) == 0 {
+			delete(idx.postings, term)
+			delete(idx.idf, term)
+			idx.removeTrigrams(term)
+		}
+	}
+
+	for field, tokens := range entry.fields {
+		idx.fieldTotals[field] -= len(tokens)
+	}
+	delete(idx.docs, id)
+	idx.suggestions.removeDocument(&entry.doc)
+	return true
+}
+
+// hasPhrase reports whether any field contains words as consecutive tokens.
+func (entry *indexedDocument) hasPhrase(words []string) bool {
+	for _, tokens := range entry.fields {
+		if containsSequence(tokens, words) {
+			return true
+		}
+	}
+	return false
+}
+
+func (idx *invertedIndex) upsert(doc models.SearchDocument) {
+	idx.delete(doc.ID)
+	idx.insert(doc)
+	idx.recomputeIDF()
+	idx.semantic.add(idx.docs[doc.ID])
+}
+
+func (idx *invertedIndex) remove(id string) {
+	if idx.delete(id) {
+		idx.recomputeIDF()
+		idx.semantic.remove(id)
+	}
+}
+
+// IDF depends on the corpus size, so any insert or delete shifts every term.
+// The vocabulary of a portfolio is small enough to recompute it wholesale.
+func (idx *invertedIndex) recomputeIDF() {
+	docCount := float64(len(idx.docs))
+	for term, posting := range idx.postings {
+		df := float64(len(posting))
+		idx.idf[term] = math.Log((docCount-df+0.5)/(df+0.5) + 1)
+	}
+}
+
+func (idx *invertedIndex) addTrigrams(term string) {
+	for _, gram := range util.Trigrams(term) {
+		terms, ok := idx.trigrams[gram]
+		if !ok {
+			terms = make(map[string]struct{}, 4)
+			idx.trigrams[gram] = terms
+		}
+		terms[term] = struct{}{}
+	}
+}
+
+func (idx *invertedIndex) removeTrigrams(term string) {
+	for _, gram := range util.Trigrams(term) {
+		terms := idx.trigrams[gram]
+		delete(terms, term)
+		if len(terms) == 0 {
+			delete(idx.trigrams, gram)
+		}
+	}
+}
+
+func (idx *invertedIndex) avgFieldLength(field string) float64 {
+	if len(idx.docs) == 0 {
+		return 0
+	}
+	return float64(idx.fieldTotals[field]) / float64(len(idx.docs))
+}
+
+func projectSearchDocument(p *models.Project) models.SearchDocument {
+	var years []int
+	if !p.CreatedAt.IsZero() {
+		years = []int{p.CreatedAt.Year()}
+	}
+
+	return models.SearchDocument{
+		ID:          p.ID.Hex(),
+		Type:        "project",
+		Title:       p.ProjectName,
+		Subtitle:    p.SmallDescription,
+		Summary:     p.SmallDescription,
+		Description: p.Description,
+		Skills:      p.Skills,
+		Tokens:      p.Tokens,
+		Years:       years,
+		Hosts:       projectHosts(p),
+		URL:         "/projects/" + p.ID.Hex(),
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
+
+func experienceSearchDocument(e *models.Experience) models.SearchDocument {
+	subtitle := ""
+	if len(e.ExperienceTimeline) > 0 {
+		subtitle = e.ExperienceTimeline[0].Position
+	}
+
+	positions := make([]string, 0, len(e.ExperienceTimeline))
+	years := make([]int, 0, 4)
+	for _, t := range e.ExperienceTimeline {
+		positions = append(positions, t.Position)
+		years = append(years, util.YearSpan(t.StartDate, t.EndDate)...)
+	}
+
+	return models.SearchDocument{
+		ID:          e.ID.Hex(),
+		Type:        "experience",
+		Title:       e.CompanyName,
+		Subtitle:    subtitle,
+		Description: e.Description,
+		Skills:      e.Technologies,
+		Tokens:      e.Tokens,
+		Years:       uniqueYears(years),
+		Positions:   uniqueFold(positions),
+		URL:         "/experiences/" + e.ID.Hex(),
+		UpdatedAt:   e.UpdatedAt,
+	}
+}
+
+func certificationSearchDocument(c *models.CertificationOrAchievements) models.SearchDocument {
+	var years []int
+	if year, ok := util.ExtractYear(c.IssueDate); ok {
+		years = []int{year}
+	}
+
+	return models.SearchDocument{
+		ID:          c.ID.Hex(),
+		Type:        "certificate",
+		Title:       c.Title,
+		Subtitle:    c.Issuer,
+		Issuer:      c.Issuer,
+		Description: c.Description,
+		Skills:      c.Skills,
+		Tokens:      c.Tokens,
+		Years:       years,
+		URL:         "/certificates/" + c.ID.Hex(),
+		UpdatedAt:   c.UpdatedAt,
+	}
+}
+
+func volunteerSearchDocument(v *models.VolunteerExperience) models.SearchDocument {
+	subtitle := ""
+	if len(v.VolunteerTimeLine) > 0 {
+		subtitle = v.VolunteerTimeLine[0].PositionOfAuthority
+	}
+
+	positions := make([]string, 0, len(v.VolunteerTimeLine))
+	years := make([]int, 0, 4)
+	for _, t := range v.VolunteerTimeLine {
+		positions = append(positions, t.PositionOfAuthority)
+		years = append(years, util.YearSpan(t.StartDate, t.EndDate)...)
+	}
+
+	return models.SearchDocument{
+		ID:          v.ID.Hex(),
+		Type:        "volunteer",
+		Title:       v.Organisation,
+		Subtitle:    subtitle,
+		Description: v.Description,
+		Skills:      v.Technologies,
+		Tokens:      v.Tokens,
+		Years:       uniqueYears(years),
+		Positions:   uniqueFold(positions),
+		URL:         "/volunteer/" + v.ID.Hex(),
+		UpdatedAt:   v.UpdatedAt,
+	}
+}
@@ -0,0 +1,430 @@
+package controller
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/MishraShardendu22/util"
+)
+
+// Supported query syntax:
+//
+//	kubernetes operator      free terms, ranked by BM25 (any may match)
+//	"service mesh"           phrase, must appear in that order
+//	-fiber                   exclusion, also -"phrase" and -skill:fiber
+//	skill:go OR skill:rust   OR joins neighbouring clauses into one group
+//	skill: company: org: issuer: role: type:   field qualifiers
+//	year:2023 year:2021..2023                   year or inclusive range
+//
+// Phrases, qualifiers and OR groups are required; a lone free term only
+// affects ranking once anything else in the query is required. Every clause
+// also matches the synonyms of its words (see applySynonyms).
+
+type clauseKind int
+
+const (
+	clauseTerm clauseKind = iota
+	clausePhrase
+	clauseField
+)
+
+var queryFields = map[string]string{
+	"skill":        "skill",
+	"skills":       "skill",
+	"tech":         "skill",
+	"company":      "company",
+	"org":          "organisation",
+	"organisation": "organisation",
+	"organization": "organisation",
+	"issuer":       "issuer",
+	"role":         "position",
+	"position":     "position",
+	"type":         "type",
+	"year":         "year",
+}
+
+var searchTypeAliases = map[string]string{
+	"project":        "project",
+	"projects":       "project",
+	"experience":     "experience",
+	"experiences":    "experience",
+	"work":           "experience",
+	"certificate":    "certificate",
+	"certificates":   "certificate",
+	"certification":  "certificate",
+	"certifications": "certificate",
+	"cert":           "certificate",
+	"volunteer":      "volunteer",
+	"volunteering":   "volunteer",
+}
+
+type queryClause struct {
+	words    []string
+	synonyms [][]string // analyzed alternatives to words
+	kind     clauseKind
+	field    string
+	value    string
+	text     string
+	negated  bool
+}
+
+type clauseGroup []queryClause
+
+func (g clauseGroup) required() bool {
+	return len(g) > 1 || g[0].kind != clauseTerm
+}
+
+type parsedQuery struct {
+	groups   []clauseGroup
+	excluded []queryClause
+}
+
+type rawQueryUnit struct {
+	text    string
+	field   string
+	quoted  bool
+	negated bool
+}
+
+func lexQuery(query string) []rawQueryUnit {
+	runes := []rune(query)
+	units := make([]rawQueryUnit, 0, 8)
+
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		var unit rawQueryUnit
+		if runes[i] == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			unit.negated = true
+			i++
+		}
+
+		j := i
+		for j < len(runes) && unicode.IsLetter(runes[j]) {
+			j++
+		}
+		if j > i && j < len(runes) && runes[j] == ':' {
+			if field, ok := queryFields[strings.ToLower(string(runes[i:j]))]; ok {
+				unit.field = field
+				i = j + 1
+			}
+		}
+
+		if i < len(runes) && runes[i] == '"' {
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			unit.text = string(runes[i+1 : end])
+			unit.quoted = true
+			i = end + 1
+		} else {
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) {
+				end++
+			}
+			unit.text = string(runes[i:end])
+			i = end
+		}
+
+		units = append(units, unit)
+	}
+
+	return units
+}
+
+func buildClause(unit rawQueryUnit) (queryClause, bool) {
+	clause := queryClause{field: unit.field, text: unit.text, negated: unit.negated}
+
+	switch unit.field {
+	case "type":
+		clause.kind = clauseField
+		clause.value = strings.ToLower(strings.TrimSpace(unit.text))
+		if alias, ok := searchTypeAliases[clause.value]; ok {
+			clause.value = alias
+		}
+		return clause, clause.value != ""
+	case "year":
+		clause.kind = clauseField
+		clause.value = strings.TrimSpace(unit.text)
+		_, _, ok := parseYearRange(clause.value)
+		return clause, ok
+	}
+
+	clause.words = util.Tokenize(unit.text)
+	if len(clause.words) == 0 {
+		return clause, false
+	}
+
+	switch {
+	case unit.field != "":
+		clause.kind = clauseField
+	case unit.quoted:
+		clause.kind = clausePhrase
+	default:
+		clause.kind = clauseTerm
+	}
+	return clause, true
+}
+
+func parseQuery(query string) parsedQuery {
+	var pq parsedQuery
+	pendingOr := false
+
+	for _, unit := range lexQuery(query) {
+		if unit.text == "OR" && !unit.quoted && !unit.negated && unit.field == "" {
+			pendingOr = len(pq.groups) > 0
+			continue
+		}
+
+		clause, ok := buildClause(unit)
+		if !ok {
+			pendingOr = false
+			continue
+		}
+
+		switch {
+		case clause.negated:
+			pq.excluded = append(pq.excluded, clause)
+		case pendingOr:
+			last := len(pq.groups) - 1
+			pq.groups[last] = append(pq.groups[last], clause)
+		default:
+			pq.groups = append(pq.groups, clauseGroup{clause})
+		}
+		pendingOr = false
+	}
+
+	return pq
+}
+
+func (pq *parsedQuery) hasRequired() bool {
+	for _, g := range pq.groups {
+		if g.required() {
+			return true
+		}
+	}
+	return false
+}
+
+// needsFullScan reports whether a required group can be satisfied by a
+// clause that has no posting list (type: and year:), in which case the
+// candidates cannot be drawn from the inverted index alone.
+func (pq *parsedQuery) needsFullScan() bool {
+	for _, g := range pq.groups {
+		if !g.required() {
+			continue
+		}
+		for _, clause := range g {
+			if clause.field == "type" || clause.field == "year" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scoringWords splits the positive words of the query into free terms, which
+// may be fuzzily expanded, and exact words from phrases and qualifiers.
+func (pq *parsedQuery) scoringWords() (free []string, exact []string) {
+	for _, g := range pq.groups {
+		for _, clause := range g {
+			if clause.kind == clauseTerm {
+				free = append(free, clause.words...)
+			} else {
+				exact = append(exact, clause.words...)
+			}
+		}
+	}
+	return free, exact
+}
+
+// accepts applies exclusions and required groups to a document. expansions
+// maps each free query word to the vocabulary terms it was expanded into.
+func (pq *parsedQuery) accepts(entry *indexedDocument, expansions map[string][]string) bool {
+	for i := range pq.excluded {
+		if pq.excluded[i].matches(entry, nil) {
+			return false
+		}
+	}
+
+	for _, g := range pq.groups {
+		if !g.required() {
+			continue
+		}
+		matched := false
+		for i := range g {
+			if g[i].matches(entry, expansions) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *queryClause) matches(entry *indexedDocument, expansions map[string][]string) bool {
+	switch c.field {
+	case "type":
+		return entry.doc.Type == c.value
+	case "year":
+		from, to, ok := parseYearRange(c.value)
+		return ok && hasYearIn(entry.doc.Years, from, to)
+	}
+
+	if c.kind == clauseTerm {
+		for _, word := range c.words {
+			terms, ok := expansions[word]
+			if !ok {
+				terms = []string{word}
+			}
+			for _, term := range terms {
+				if entry.termFreq[term] > 0 {
+					return true
+				}
+			}
+		}
+		return c.matchesSequence(entry, c.synonyms)
+	}
+	return c.matchesSequence(entry, [][]string{c.words}) || c.matchesSequence(entry, c.synonyms)
+}
+
+// matchesSequence checks the clause's words, or one of their synonyms, in
+// the place the clause kind or field qualifier points at.
+func (c *queryClause) matchesSequence(entry *indexedDocument, sequences [][]string) bool {
+	for _, words := range sequences {
+		switch {
+		case c.kind != clauseField:
+			if entry.hasPhrase(words) {
+				return true
+			}
+		case c.field == "skill":
+			for _, skill := range entry.doc.Skills {
+				if containsSequence(util.Tokenize(skill), words) {
+					return true
+				}
+			}
+		case c.field == "company":
+			if entry.doc.Type == "experience" && containsSequence(entry.fields[fieldTitle], words) {
+				return true
+			}
+		case c.field == "organisation":
+			if entry.doc.Type == "volunteer" && containsSequence(entry.fields[fieldTitle], words) {
+				return true
+			}
+		case c.field == "issuer":
+			if containsSequence(entry.fields[fieldIssuer], words) {
+				return true
+			}
+		case c.field == "position":
+			if containsSequence(entry.fields[fieldPositions], words) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseYearRange reads "2023", "2021..2023" or "2021-2023" as an inclusive
+// range of years.
+func parseYearRange(value string) (from, to int, ok bool) {
+	first, last, isRange := strings.Cut(value, "..")
+	if !isRange {
+		first, last, isRange = strings.Cut(value, "-")
+	}
+	if !isRange {
+		last = first
+	}
+
+	from, err := strconv.Atoi(strings.TrimSpace(first))
+	if err != nil {
+		return 0, 0, false
+	}
+	to, err = strconv.Atoi(strings.TrimSpace(last))
+	if err != nil {
+		return 0, 0, false
+	}
+	if to < from {
+		from, to = to, from
+	}
+	return from, to, true
+}
+
+func hasYearIn(years []int, from, to int) bool {
+	for _, year := range years {
+		if year >= from && year <= to {
+			return true
+		}
+	}
+	return false
+}
+
+func containsSequence(tokens, words []string) bool {
+	if len(words) == 0 {
+		return false
+	}
+	for i := 0; i+len(words) <= len(tokens); i++ {
+		if slices.Equal(tokens[i:i+len(words)], words) {
+			return true
+		}
+	}
+	return false
+}
+
+// render prints the query back in its own syntax, substituting corrected
+// spellings for free terms.
+func (pq *parsedQuery) render(corrections map[string]string) string {
+	parts := make([]string, 0, len(pq.groups)+len(pq.excluded))
+	for _, g := range pq.groups {
+		rendered := make([]string, 0, len(g))
+		for i := range g {
+			rendered = append(rendered, g[i].render(corrections))
+		}
+		parts = append(parts, strings.Join(rendered, " OR "))
+	}
+	for i := range pq.excluded {
+		parts = append(parts, pq.excluded[i].render(nil))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (c *queryClause) render(corrections map[string]string) string {
+	var b strings.Builder
+	if c.negated {
+		b.WriteByte('-')
+	}
+	if c.field != "" {
+		b.WriteString(c.field)
+		b.WriteByte(':')
+	}
+
+	switch {
+	case c.kind == clauseTerm && len(corrections) > 0:
+		// Corrections are keyed by analyzed tokens. They are located in the
+		// text as typed, so words that need no fix keep their spelling
+		// instead of showing up stemmed.
+		runes := []rune(c.text)
+		last := 0
+		for _, span := range util.TokenSpans(c.text) {
+			if fixed, ok := corrections[span.Token]; ok {
+				b.WriteString(string(runes[last:span.Start]))
+				b.WriteString(fixed)
+				last = span.End
+			}
+		}
+		b.WriteString(string(runes[last:]))
+	case c.kind == clausePhrase || strings.ContainsFunc(c.text, unicode.IsSpace):
+		b.WriteString(strconv.Quote(c.text))
+	default:
+		b.WriteString(c.text)
+	}
+	return b.String()
+}
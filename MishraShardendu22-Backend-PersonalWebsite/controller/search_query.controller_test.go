@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/MishraShardendu22/util"
+)
+
+func TestRenderKeepsTypedWords(t *testing.T) {
+	if util.Tokenize("deploying")[0] == "deploying" {
+		t.Fatal("the test needs a word that stemming changes")
+	}
+	corrections := map[string]string{util.Tokenize("postgre")[0]: "postgresql"}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"postgre deploying", "postgresql deploying"},
+		{"Deploying OR postgre", "Deploying OR postgresql"},
+		{"postgre -deploying", "postgresql -deploying"},
+		{`postgre "deploying apps"`, `postgresql "deploying apps"`},
+	}
+	for _, tt := range tests {
+		pq := parseQuery(tt.query)
+		if got := pq.render(corrections); got != tt.want {
+			t.Errorf("render(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/MishraShardendu22/models"
+	"github.com/MishraShardendu22/util"
+	"github.com/gofiber/fiber/v2"
+)
+
+const reindexTimeout = 10 * time.Minute
+
+// Only one reindex runs at a time; its progress stays readable afterwards.
+var (
+	reindexStatus models.ReindexStatus
+	reindexMutex  sync.Mutex
+)
+
+func reindexSnapshot() models.ReindexStatus {
+	reindexMutex.Lock()
+	defer reindexMutex.Unlock()
+	snapshot := reindexStatus
+	snapshot.Counts = maps.Clone(reindexStatus.Counts)
+	return snapshot
+}
+
+func StartReindex(c *fiber.Ctx) error {
+	reindexMutex.Lock()
+	if reindexStatus.Running {
+		reindexMutex.Unlock()
+		return util.ResponseAPI(c, fiber.StatusConflict, "Reindex already running", reindexSnapshot(), "")
+	}
+	reindexStatus = models.ReindexStatus{
+		Counts:    make(map[string]models.ReindexCount, 4),
+		StartedAt: time.Now(),
+		Running:   true,
+	}
+	reindexMutex.Unlock()
+
+	go runReindex()
+	return util.ResponseAPI(c, fiber.StatusAccepted, "Reindex started", reindexSnapshot(), "")
+}
+
+func GetReindexStatus(c *fiber.Ctx) error {
+	return util.ResponseAPI(c, fiber.StatusOK, "Reindex status", reindexSnapshot(), "")
+}
+
+// runReindex recomputes the stored tokens of every collection, then builds a
+// new in-memory index from them and swaps it in.
+func runReindex() {
+	ctx, cancel := context.WithTimeout(context.Background(), reindexTimeout)
+	defer cancel()
+
+	_, err := RebuildStoredTokens(ctx, func(collection string, count models.ReindexCount) {
+		reindexMutex.Lock()
+		reindexStatus.Collection = collection
+		reindexStatus.Counts[collection] = count
+		reindexMutex.Unlock()
+	})
+
+	if err == nil {
+		var meta *models.SearchMeta
+		if meta, err = loadSearchMeta(); err == nil {
+			err = saveAnalyzerSignature(meta, storedTokensSignature())
+		}
+	}
+
+	documents := 0
+	if err == nil {
+		var idx *invertedIndex
+		if idx, err = rebuildSearchIndex(); err == nil {
+			documents = len(idx.docs)
+		}
+	}
+
+	reindexMutex.Lock()
+	reindexStatus.Running = false
+	reindexStatus.Collection = ""
+	reindexStatus.FinishedAt = time.Now()
+	reindexStatus.Documents = documents
+	if err != nil {
+		reindexStatus.Error = err.Error()
+	}
+	status := reindexStatus
+	reindexMutex.Unlock()
+
+	if err != nil {
+		slog.Error("Search reindex failed", "error", err)
+		return
+	}
+	slog.Info("Search reindex finished",
+		"documents", documents,
+		"duration", status.FinishedAt.Sub(status.StartedAt),
+	)
+}
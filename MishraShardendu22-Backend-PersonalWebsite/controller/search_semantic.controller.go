@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/MishraShardendu22/util"
+)
+
+// Search modes accepted by the mode query parameter.
+const (
+	modeKeyword  = "keyword"
+	modeSemantic = "semantic"
+	modeHybrid   = "hybrid"
+)
+
+const (
+	lshTables = 8
+	lshBits   = 6
+	lshSeed   = 22
+	// Cosine below this is treated as unrelated rather than a weak match.
+	minSemanticScore = 0.15
+	// Reciprocal-rank fusion constant; 60 is the value from the original paper.
+	rrfK = 60
+	// Semantic neighbours are looked up this many times the page size, so
+	// enough survive the filters while the LSH buckets still narrow the scan.
+	semanticOverfetch = 3
+)
+
+// semanticIndex holds a vector per document next to the inverted index. The
+// embedder is fitted when the index is built; documents patched in later are
+// folded into the existing space until the next rebuild refits it.
+type semanticIndex struct {
+	embedder util.Embedder
+	ann      *util.LSHIndex
+}
+
+func newSemanticIndex(docs map[string]*indexedDocument) *semanticIndex {
+	// Fit in ID order so a rebuild of the same documents lands on the same space.
+	ids := make([]string, 0, len(docs))
+	for id := range docs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	corpus := make([][]string, 0, len(docs))
+	for _, id := range ids {
+		corpus = append(corpus, docs[id].tokens())
+	}
+
+	embedder := util.DefaultEmbedderFactory()(corpus)
+	s := &semanticIndex{
+		embedder: embedder,
+		ann:      util.NewLSHIndex(embedder.Dimensions(), lshTables, lshBits, lshSeed),
+	}
+	for _, entry := range docs {
+		s.add(entry)
+	}
+	return s
+}
+
+// tokens returns every analyzed token of the document in field order.
+func (entry *indexedDocument) tokens() []string {
+	tokens := make([]string, 0, 64)
+	for _, field := range searchFields {
+		tokens = append(tokens, entry.fields[field]...)
+	}
+	return tokens
+}
+
+func (s *semanticIndex) add(entry *indexedDocument) {
+	s.ann.Add(entry.doc.ID, s.embedder.Embed(entry.tokens()))
+}
+
+func (s *semanticIndex) remove(id string) {
+	s.ann.Remove(id)
+}
+
+// semanticQuery lists the words a query is embedded from: all its analyzed
+// words, including those the index has never seen, whose trigrams still
+// carry meaning for the hashing embedder, then the terms they expanded to.
+func semanticQuery(words []string, queryTerms []queryTerm) []string {
+	tokens := slices.Clone(words)
+	for _, qt := range queryTerms {
+		if !slices.Contains(tokens, qt.token) {
+			tokens = append(tokens, qt.token)
+		}
+	}
+	return tokens
+}
+
+// search returns the cosine similarity of the k documents closest to the
+// query tokens, leaving out those below minSemanticScore.
+func (s *semanticIndex) search(tokens []string, k int) map[string]float64 {
+	scores := make(map[string]float64, k)
+	if len(tokens) == 0 {
+		return scores
+	}
+	for _, n := range s.ann.Search(s.embedder.Embed(tokens), k) {
+		if n.Score >= minSemanticScore {
+			scores[n.ID] = n.Score
+		}
+	}
+	return scores
+}
+
+// reciprocalRankFusion sums 1/(rrfK+rank) over every ranking a document
+// appears in. Only documents with a positive score count as ranked.
+func reciprocalRankFusion(rankings ...map[string]float64) map[string]float64 {
+	fused := make(map[string]float64, 16)
+	for _, scores := range rankings {
+		ids := make([]string, 0, len(scores))
+		for id, score := range scores {
+			if score > 0 {
+				ids = append(ids, id)
+			}
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			if scores[ids[i]] != scores[ids[j]] {
+				return scores[ids[i]] > scores[ids[j]]
+			}
+			return ids[i] < ids[j]
+		})
+		for rank, id := range ids {
+			fused[id] += 1 / float64(rrfK+rank+1)
+		}
+	}
+	return fused
+}
@@ -0,0 +1,31 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/MishraShardendu22/models"
+	"github.com/MishraShardendu22/util"
+)
+
+func TestSemanticSearchEmbedsUnknownWords(t *testing.T) {
+	old := util.DefaultEmbedderFactory()
+	util.SetDefaultEmbedderFactory(util.HashingEmbedderFactory(256))
+	t.Cleanup(func() { util.SetDefaultEmbedderFactory(old) })
+
+	idx := newInvertedIndex([]models.SearchDocument{
+		{ID: "k8s", Type: "project", Title: "Kubernetes operator", Description: "Reconciles kubernetes clusters"},
+		{ID: "art", Type: "project", Title: "Watercolor gallery", Description: "Paintings of mountain lakes"},
+	})
+
+	words := util.Tokenize("kubernetess")
+	if _, ok := idx.postings[words[0]]; ok {
+		t.Fatal("the test needs a word the index does not know")
+	}
+	// Without fuzzy expansion the keyword side has nothing to offer.
+	queryTerms, _ := expandQuery(idx, words, false)
+
+	scores := idx.semantic.search(semanticQuery(words, queryTerms), 10)
+	if scores["k8s"] <= scores["art"] {
+		t.Errorf("scores = %v, want k8s ahead", scores)
+	}
+}
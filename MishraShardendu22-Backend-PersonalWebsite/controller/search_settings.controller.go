@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/MishraShardendu22/models"
+	"github.com/MishraShardendu22/util"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kamva/mgm/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Ranking settings used while scoring. They are reloaded from Mongo whenever
+// the index is rebuilt, so changes made on one replica reach the others
+// within indexCacheTTL.
+var (
+	rankingSettings      = defaultSearchSettings()
+	rankingSettingsMutex sync.RWMutex
+)
+
+func defaultSearchSettings() models.SearchSettings {
+	return models.SearchSettings{
+		FieldWeights: map[string]float64{
+			fieldTitle:       3,
+			fieldSubtitle:    1.5,
+			fieldDescription: 1,
+			fieldSkills:      2,
+			fieldPositions:   2,
+			fieldIssuer:      1.5,
+			fieldLinks:       0.5,
+		},
+		FieldB: map[string]float64{
+			fieldTitle:       0.5,
+			fieldSubtitle:    0.5,
+			fieldDescription: 0.75,
+			fieldSkills:      0.5,
+			fieldPositions:   0.5,
+			fieldIssuer:      0.5,
+			fieldLinks:       0.5,
+		},
+		TypeBoosts: map[string]float64{
+			"project":     1,
+			"experience":  1,
+			"certificate": 1,
+			"volunteer":   1,
+		},
+		K1:                  1.2,
+		RecencyWeight:       0,
+		RecencyHalfLifeDays: 365,
+	}
+}
+
+// mergeSearchSettings overlays the stored values on the defaults so a partial
+// document, or one written before a field existed, still scores every field.
+func mergeSearchSettings(stored *models.SearchSettings) models.SearchSettings {
+	merged := defaultSearchSettings()
+	merged.DefaultModel = stored.DefaultModel
+	maps.Copy(merged.FieldWeights, stored.FieldWeights)
+	maps.Copy(merged.FieldB, stored.FieldB)
+	maps.Copy(merged.TypeBoosts, stored.TypeBoosts)
+	if stored.K1 != 0 {
+		merged.K1 = stored.K1
+	}
+	if stored.RecencyHalfLifeDays != 0 {
+		merged.RecencyHalfLifeDays = stored.RecencyHalfLifeDays
+	}
+	merged.RecencyWeight = stored.RecencyWeight
+	return merged
+}
+
+func validateSearchSettings(s *models.SearchSettings) error {
+	if s.K1 <= 0 {
+		return errors.New("k1 must be positive")
+	}
+	for field, w := range s.FieldWeights {
+		if !slices.Contains(searchFields, field) {
+			return fmt.Errorf("unknown field %q", field)
+		}
+		if w < 0 {
+			return fmt.Errorf("weight of %s must not be negative", field)
+		}
+	}
+	for field, b := range s.FieldB {
+		if !slices.Contains(searchFields, field) {
+			return fmt.Errorf("unknown field %q", field)
+		}
+		if b < 0 || b > 1 {
+			return fmt.Errorf("b of %s must be between 0 and 1", field)
+		}
+	}
+	for t, boost := range s.TypeBoosts {
+		if !slices.Contains(searchTypes, t) {
+			return fmt.Errorf("unknown type %q", t)
+		}
+		if boost < 0 {
+			return fmt.Errorf("boost of %s must not be negative", t)
+		}
+	}
+	if s.RecencyWeight < 0 {
+		return errors.New("recency_weight must not be negative")
+	}
+	if s.RecencyHalfLifeDays <= 0 {
+		return errors.New("recency_half_life_days must be positive")
+	}
+	return nil
+}
+
+// LoadSearchSettings refreshes the ranking settings from Mongo. On failure the
+// previous settings stay in effect.
+func LoadSearchSettings() error {
+	stored := &models.SearchSettings{}
+	err := mgm.Coll(stored).First(bson.M{}, stored)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+
+	merged := mergeSearchSettings(stored)
+	rankingSettingsMutex.Lock()
+	rankingSettings = merged
+	rankingSettingsMutex.Unlock()
+	return nil
+}
+
+func currentSearchSettings() models.SearchSettings {
+	rankingSettingsMutex.RLock()
+	defer rankingSettingsMutex.RUnlock()
+	return rankingSettings
+}
+
+// recencyBoost grows towards 1+RecencyWeight for recently updated documents
+// and halves its excess every RecencyHalfLifeDays.
+func recencyBoost(s *models.SearchSettings, updatedAt time.Time, now time.Time) float64 {
+	if s.RecencyWeight == 0 || updatedAt.IsZero() {
+		return 1
+	}
+	ageDays := max(now.Sub(updatedAt).Hours()/24, 0)
+	return 1 + s.RecencyWeight*math.Exp2(-ageDays/s.RecencyHalfLifeDays)
+}
+
+func GetSearchSettings(c *fiber.Ctx) error {
+	return util.ResponseAPI(c, fiber.StatusOK, "Search settings", currentSearchSettings(), "")
+}
+
+func UpdateSearchSettings(c *fiber.Ctx) error {
+	stored := &models.SearchSettings{}
+	err := mgm.Coll(stored).First(bson.M{}, stored)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to load search settings", nil, "")
+	}
+
+	// The body is decoded over the stored settings, so values and map keys it
+	// leaves out keep what was stored. A null map goes back to the defaults.
+	settings := mergeSearchSettings(stored)
+	if err := c.BodyParser(&settings); err != nil {
+		return util.ResponseAPI(c, fiber.StatusBadRequest, "Invalid request body", nil, "")
+	}
+	settings = mergeSearchSettings(&settings)
+	if err := validateSearchSettings(&settings); err != nil {
+		return util.ResponseAPI(c, fiber.StatusBadRequest, err.Error(), nil, "")
+	}
+
+	if settings.ID.IsZero() {
+		err = mgm.Coll(&settings).Create(&settings)
+	} else {
+		err = mgm.Coll(&settings).Update(&settings)
+	}
+	if err != nil {
+		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to save search settings", nil, "")
+	}
+
+	rankingSettingsMutex.Lock()
+	rankingSettings = settings
+	rankingSettingsMutex.Unlock()
+	return util.ResponseAPI(c, fiber.StatusOK, "Search settings updated successfully", settings, "")
+}
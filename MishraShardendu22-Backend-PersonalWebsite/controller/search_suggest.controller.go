@@ -0,0 +1,255 @@
+package controller
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/MishraShardendu22/models"
+)
+
+const maxSuggestions = 8
+
+// Entity weights used to rank suggestions of different kinds against each
+// other. Skills are shared across collections and are what visitors type most.
+var suggestionTypeWeights = map[string]float64{
+	"skill":        1.5,
+	"project":      1.2,
+	"company":      1.1,
+	"certificate":  1.0,
+	"organisation": 0.9,
+}
+
+// titleSuggestionTypes maps a search document type to the suggestion type of
+// its title.
+var titleSuggestionTypes = map[string]string{
+	"project":     "project",
+	"experience":  "company",
+	"certificate": "certificate",
+	"volunteer":   "organisation",
+}
+
+type suggestionEntry struct {
+	text  string
+	kind  string
+	count int
+}
+
+func (e *suggestionEntry) score() float64 {
+	return float64(e.count) * suggestionTypeWeights[e.kind]
+}
+
+type trieNode struct {
+	children map[rune]*trieNode
+	entries  map[string]*suggestionEntry
+}
+
+func newTrieNode() *trieNode {
+	return &trieNode{children: make(map[rune]*trieNode, 2)}
+}
+
+// suggestionTrie indexes every suggestion under the start of each of its
+// words, so "port" completes both "Portainer" and "Cluster Portfolio".
+type suggestionTrie struct {
+	root    *trieNode
+	entries map[string]*suggestionEntry // kind + lowercase text -> entry
+}
+
+func newSuggestionTrie() *suggestionTrie {
+	return &suggestionTrie{
+		root:    newTrieNode(),
+		entries: make(map[string]*suggestionEntry, 128),
+	}
+}
+
+func suggestionKey(kind, text string) string {
+	return kind + "\x00" + strings.ToLower(text)
+}
+
+// wordStarts returns every suffix of the lowercased text that begins at a word
+// boundary.
+func wordStarts(text string) []string {
+	lower := []rune(strings.ToLower(strings.TrimSpace(text)))
+	starts := make([]string, 0, 2)
+	for i, r := range lower {
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) {
+			continue
+		}
+		if i == 0 || !(unicode.IsLetter(lower[i-1]) || unicode.IsNumber(lower[i-1])) {
+			starts = append(starts, string(lower[i:]))
+		}
+	}
+	return starts
+}
+
+func (t *suggestionTrie) add(kind, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+
+	key := suggestionKey(kind, text)
+	if entry, ok := t.entries[key]; ok {
+		entry.count++
+		return
+	}
+
+	entry := &suggestionEntry{text: text, kind: kind, count: 1}
+	t.entries[key] = entry
+	for _, suffix := range wordStarts(text) {
+		node := t.root
+		for _, r := range suffix {
+			child, ok := node.children[r]
+			if !ok {
+				child = newTrieNode()
+				node.children[r] = child
+			}
+			node = child
+		}
+		if node.entries == nil {
+			node.entries = make(map[string]*suggestionEntry, 1)
+		}
+		node.entries[key] = entry
+	}
+}
+
+func (t *suggestionTrie) remove(kind, text string) {
+	text = strings.TrimSpace(text)
+	key := suggestionKey(kind, text)
+	entry, ok := t.entries[key]
+	if !ok {
+		return
+	}
+
+	entry.count--
+	if entry.count > 0 {
+		return
+	}
+
+	delete(t.entries, key)
+	for _, suffix := range wordStarts(text) {
+		t.root.prune([]rune(suffix), key)
+	}
+}
+
+// prune drops key from the node at the end of path and removes any nodes
+// left without entries or children on the way back up.
+func (n *trieNode) prune(path []rune, key string) bool {
+	if len(path) == 0 {
+		delete(n.entries, key)
+	} else if child, ok := n.children[path[0]]; ok {
+		if child.prune(path[1:], key) {
+			delete(n.children, path[0])
+		}
+	}
+	return len(n.entries) == 0 && len(n.children) == 0
+}
+
+func (t *suggestionTrie) addDocument(doc *models.SearchDocument) {
+	t.add(titleSuggestionTypes[doc.Type], doc.Title)
+	for _, skill := range uniqueFold(doc.Skills) {
+		t.add("skill", skill)
+	}
+}
+
+func (t *suggestionTrie) removeDocument(doc *models.SearchDocument) {
+	t.remove(titleSuggestionTypes[doc.Type], doc.Title)
+	for _, skill := range uniqueFold(doc.Skills) {
+		t.remove("skill", skill)
+	}
+}
+
+// complete returns the highest ranked suggestions whose words start with
+// prefix.
+func (t *suggestionTrie) complete(prefix string, limit int) []models.SearchSuggestion {
+	node := t.root
+	for _, r := range strings.ToLower(prefix) {
+		child, ok := node.children[r]
+		if !ok {
+			return []models.SearchSuggestion{}
+		}
+		node = child
+	}
+
+	found := make(map[string]*suggestionEntry, 16)
+	var walk func(n *trieNode)
+	walk = func(n *trieNode) {
+		for key, entry := range n.entries {
+			found[key] = entry
+		}
+		for _, child := range n.children {
+			walk(child)
+		}
+	}
+	walk(node)
+
+	ranked := make([]*suggestionEntry, 0, len(found))
+	for _, entry := range found {
+		ranked = append(ranked, entry)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		si, sj := ranked[i].score(), ranked[j].score()
+		if si != sj {
+			return si > sj
+		}
+		return ranked[i].text < ranked[j].text
+	})
+
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	suggestions := make([]models.SearchSuggestion, 0, len(ranked))
+	for _, entry := range ranked {
+		suggestions = append(suggestions, models.SearchSuggestion{
+			Text:  entry.text,
+			Type:  entry.kind,
+			Score: math.Round(entry.score()*100) / 100,
+		})
+	}
+	return suggestions
+}
+
+// rankSuggestions merges completions gathered for several prefixes, such as
+// a query and its synonyms, dropping duplicates and keeping the best limit.
+func rankSuggestions(suggestions []models.SearchSuggestion, limit int) []models.SearchSuggestion {
+	seen := make(map[string]struct{}, len(suggestions))
+	unique := make([]models.SearchSuggestion, 0, len(suggestions))
+	for _, s := range suggestions {
+		key := suggestionKey(s.Type, s.Text)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		unique = append(unique, s)
+	}
+
+	sort.SliceStable(unique, func(i, j int) bool {
+		if unique[i].Score != unique[j].Score {
+			return unique[i].Score > unique[j].Score
+		}
+		return unique[i].Text < unique[j].Text
+	})
+	if len(unique) > limit {
+		unique = unique[:limit]
+	}
+	return unique
+}
+
+func uniqueFold(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	unique := make([]string, 0, len(values))
+	for _, v := range values {
+		key := strings.ToLower(strings.TrimSpace(v))
+		if key == "" {
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		unique = append(unique, v)
+	}
+	return unique
+}
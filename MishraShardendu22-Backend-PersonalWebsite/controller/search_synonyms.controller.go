@@ -0,0 +1,283 @@
+package controller
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/MishraShardendu22/models"
+	"github.com/MishraShardendu22/util"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kamva/mgm/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// A synonym only ranks slightly below the word the visitor actually typed.
+const synonymWeight = 0.9
+
+// synonymDictionary maps every term of a synonym group to the other terms of
+// that group, both as analyzed token sequences for matching and as lowercased
+// text for autocomplete.
+type synonymDictionary struct {
+	analyzed map[string][][]string
+	raw      map[string][]string
+	maxWords int
+}
+
+var (
+	searchSynonyms      = newSynonymDictionary(nil)
+	searchSynonymsMutex sync.RWMutex
+)
+
+func newSynonymDictionary(groups []models.Synonym) *synonymDictionary {
+	dict := &synonymDictionary{
+		analyzed: make(map[string][][]string, len(groups)*2),
+		raw:      make(map[string][]string, len(groups)*2),
+	}
+
+	for _, group := range groups {
+		terms := make([]string, 0, len(group.Terms))
+		sequences := make([][]string, 0, len(group.Terms))
+		for _, term := range uniqueFold(group.Terms) {
+			if tokens := util.Tokenize(term); len(tokens) > 0 {
+				terms = append(terms, strings.ToLower(strings.TrimSpace(term)))
+				sequences = append(sequences, tokens)
+				dict.maxWords = max(dict.maxWords, len(tokens))
+			}
+		}
+
+		for i := range sequences {
+			key := strings.Join(sequences[i], " ")
+			for j := range sequences {
+				if i == j || strings.Join(sequences[j], " ") == key {
+					continue
+				}
+				dict.analyzed[key] = appendSequence(dict.analyzed[key], sequences[j])
+				dict.raw[terms[i]] = append(dict.raw[terms[i]], terms[j])
+			}
+		}
+	}
+	return dict
+}
+
+func appendSequence(sequences [][]string, seq []string) [][]string {
+	for _, existing := range sequences {
+		if slices.Equal(existing, seq) {
+			return sequences
+		}
+	}
+	return append(sequences, seq)
+}
+
+// alternatives returns the synonyms of an analyzed token sequence.
+func (d *synonymDictionary) alternatives(words []string) [][]string {
+	if len(words) == 0 || len(words) > d.maxWords {
+		return nil
+	}
+	return d.analyzed[strings.Join(words, " ")]
+}
+
+// longestMatch finds the longest synonym key starting at words[start].
+func (d *synonymDictionary) longestMatch(words []string, start int) (int, [][]string) {
+	for n := min(d.maxWords, len(words)-start); n > 0; n-- {
+		if alts := d.analyzed[strings.Join(words[start:start+n], " ")]; len(alts) > 0 {
+			return n, alts
+		}
+	}
+	return 0, nil
+}
+
+func (d *synonymDictionary) rawAlternatives(text string) []string {
+	return d.raw[strings.ToLower(strings.TrimSpace(text))]
+}
+
+func currentSynonyms() *synonymDictionary {
+	searchSynonymsMutex.RLock()
+	defer searchSynonymsMutex.RUnlock()
+	return searchSynonyms
+}
+
+// LoadSynonyms rebuilds the in-memory dictionary from the synonyms collection.
+func LoadSynonyms() error {
+	var groups []models.Synonym
+	if err := mgm.Coll(&models.Synonym{}).SimpleFind(&groups, bson.M{}); err != nil {
+		return err
+	}
+
+	dict := newSynonymDictionary(groups)
+	searchSynonymsMutex.Lock()
+	searchSynonyms = dict
+	searchSynonymsMutex.Unlock()
+	return nil
+}
+
+// applySynonyms attaches the synonyms of every clause to it. Runs of free
+// terms are matched greedily, so typing "machine learning" also finds "ml".
+func (pq *parsedQuery) applySynonyms(dict *synonymDictionary) {
+	if dict.maxWords == 0 {
+		return
+	}
+
+	var (
+		runWords   []string
+		runClauses []*queryClause
+	)
+	flush := func() {
+		for i := 0; i < len(runWords); {
+			n, alts := dict.longestMatch(runWords, i)
+			if n == 0 {
+				i++
+				continue
+			}
+			runClauses[i].synonyms = append(runClauses[i].synonyms, alts...)
+			i += n
+		}
+		runWords, runClauses = runWords[:0], runClauses[:0]
+	}
+
+	for gi := range pq.groups {
+		g := pq.groups[gi]
+		if len(g) == 1 && g[0].kind == clauseTerm {
+			for range g[0].words {
+				runClauses = append(runClauses, &g[0])
+			}
+			runWords = append(runWords, g[0].words...)
+			continue
+		}
+		flush()
+		for i := range g {
+			g[i].synonyms = dict.alternatives(g[i].words)
+		}
+	}
+	flush()
+
+	for i := range pq.excluded {
+		pq.excluded[i].synonyms = dict.alternatives(pq.excluded[i].words)
+	}
+}
+
+// synonymTerms returns the scoring terms contributed by synonyms that are not
+// already part of the query.
+func (pq *parsedQuery) synonymTerms(existing []queryTerm) []queryTerm {
+	seen := make(map[string]struct{}, len(existing))
+	for _, qt := range existing {
+		seen[qt.token] = struct{}{}
+	}
+
+	var terms []queryTerm
+	for _, g := range pq.groups {
+		for _, clause := range g {
+			for _, seq := range clause.synonyms {
+				for _, token := range seq {
+					if _, ok := seen[token]; ok {
+						continue
+					}
+					seen[token] = struct{}{}
+					terms = append(terms, queryTerm{token: token, source: token, weight: synonymWeight})
+				}
+			}
+		}
+	}
+	return terms
+}
+
+func normalizeSynonymTerms(terms []string) []string {
+	normalized := make([]string, 0, len(terms))
+	for _, term := range uniqueFold(terms) {
+		normalized = append(normalized, strings.TrimSpace(term))
+	}
+	return normalized
+}
+
+func GetSynonyms(c *fiber.Ctx) error {
+	var groups []models.Synonym
+	if err := mgm.Coll(&models.Synonym{}).SimpleFind(&groups, bson.M{}); err != nil {
+		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to fetch synonyms", nil, "")
+	}
+	return util.ResponseAPI(c, fiber.StatusOK, "Synonyms retrieved successfully", groups, "")
+}
+
+func AddSynonym(c *fiber.Ctx) error {
+	var input models.Synonym
+	if err := c.BodyParser(&input); err != nil {
+		return util.ResponseAPI(c, fiber.StatusBadRequest, "Invalid request body", nil, "")
+	}
+
+	input.Terms = normalizeSynonymTerms(input.Terms)
+	if len(input.Terms) < 2 {
+		return util.ResponseAPI(c, fiber.StatusBadRequest, "At least two distinct terms are required", nil, "")
+	}
+
+	if err := mgm.Coll(&input).Create(&input); err != nil {
+		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to add synonym", nil, "")
+	}
+
+	if err := LoadSynonyms(); err != nil {
+		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Synonym added but failed to reload synonyms", nil, "")
+	}
+	return util.ResponseAPI(c, fiber.StatusCreated, "Synonym added successfully", input, "")
+}
+
+func UpdateSynonym(c *fiber.Ctx) error {
+	sid := c.Params("id")
+	if sid == "" {
+		return util.ResponseAPI(c, fiber.StatusBadRequest, "Synonym ID is required", nil, "")
+	}
+
+	synObjID, err := primitive.ObjectIDFromHex(sid)
+	if err != nil {
+		return util.ResponseAPI(c, fiber.StatusBadRequest, "Invalid synonym ID", nil, "")
+	}
+
+	var input models.Synonym
+	if err := c.BodyParser(&input); err != nil {
+		return util.ResponseAPI(c, fiber.StatusBadRequest, "Invalid request body", nil, "")
+	}
+
+	terms := normalizeSynonymTerms(input.Terms)
+	if len(terms) < 2 {
+		return util.ResponseAPI(c, fiber.StatusBadRequest, "At least two distinct terms are required", nil, "")
+	}
+
+	var existing models.Synonym
+	if err := mgm.Coll(&models.Synonym{}).FindByID(synObjID, &existing); err != nil {
+		return util.ResponseAPI(c, fiber.StatusNotFound, "Synonym not found", nil, "")
+	}
+
+	existing.Terms = terms
+	if err := mgm.Coll(&existing).Update(&existing); err != nil {
+		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to update synonym", nil, "")
+	}
+
+	if err := LoadSynonyms(); err != nil {
+		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Synonym updated but failed to reload synonyms", nil, "")
+	}
+	return util.ResponseAPI(c, fiber.StatusOK, "Synonym updated successfully", existing, "")
+}
+
+func RemoveSynonym(c *fiber.Ctx) error {
+	sid := c.Params("id")
+	if sid == "" {
+		return util.ResponseAPI(c, fiber.StatusBadRequest, "Synonym ID is required", nil, "")
+	}
+
+	synObjID, err := primitive.ObjectIDFromHex(sid)
+	if err != nil {
+		return util.ResponseAPI(c, fiber.StatusBadRequest, "Invalid synonym ID", nil, "")
+	}
+
+	var existing models.Synonym
+	if err := mgm.Coll(&models.Synonym{}).FindByID(synObjID, &existing); err != nil {
+		return util.ResponseAPI(c, fiber.StatusNotFound, "Synonym not found", nil, "")
+	}
+
+	if err := mgm.Coll(&existing).Delete(&existing); err != nil {
+		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to delete synonym", nil, "")
+	}
+
+	if err := LoadSynonyms(); err != nil {
+		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Synonym deleted but failed to reload synonyms", nil, "")
+	}
+	return util.ResponseAPI(c, fiber.StatusOK, "Synonym deleted successfully", nil, "")
+}
@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+
+	"github.com/MishraShardendu22/models"
+	"github.com/MishraShardendu22/util"
+	"github.com/kamva/mgm/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// storedTokensVersion changes whenever the builders below index different
+// fields, so existing documents are retokenized on the next start.
+const storedTokensVersion = 2
+
+func projectTokens(p *models.Project) []string {
+	return util.GenerateTokens([]string{p.ProjectName, p.Description, p.SmallDescription, hostWords(projectHosts(p))}, p.Skills)
+}
+
+func experienceTokens(e *models.Experience) []string {
+	fields := []string{e.CompanyName, e.Description}
+	for _, t := range e.ExperienceTimeline {
+		fields = append(fields, t.Position)
+	}
+	return util.GenerateTokens(fields, e.Technologies)
+}
+
+func certificationTokens(c *models.CertificationOrAchievements) []string {
+	return util.GenerateTokens([]string{c.Title, c.Issuer, c.Description}, c.Skills)
+}
+
+func volunteerTokens(v *models.VolunteerExperience) []string {
+	fields := []string{v.Organisation, v.Description}
+	for _, t := range v.VolunteerTimeLine {
+		fields = append(fields, t.PositionOfAuthority)
+	}
+	return util.GenerateTokens(fields, v.Technologies)
+}
+
+// storedTokensSignature identifies both the analyzer and the token builders
+// behind the stored tokens.
+func storedTokensSignature() string {
+	return util.DefaultAnalyzer().Signature() + "/v" + strconv.Itoa(storedTokensVersion)
+}
+
+const reindexBatchSize = 100
+
+// reindexProgress is told the running counts of a collection after every batch.
+type reindexProgress func(collection string, count models.ReindexCount)
+
+// retokenizeCollection recomputes the stored tokens of every document in the
+// collection of PT, writing them back in bulk batches.
+func retokenizeCollection[T any, PT interface {
+	*T
+	mgm.Model
+}](ctx context.Context, name string, tokens func(PT) []string, progress reindexProgress) (models.ReindexCount, error) {
+	var count models.ReindexCount
+	coll := mgm.Coll(PT(new(T)))
+
+	total, err := coll.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return count, err
+	}
+	count.Total = int(total)
+
+	cursor, err := coll.Find(ctx, bson.M{})
+	if err != nil {
+		return count, err
+	}
+	defer cursor.Close(ctx)
+
+	batch := make([]mongo.WriteModel, 0, reindexBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		res, err := coll.BulkWrite(ctx, batch, options.BulkWrite().SetOrdered(false))
+		if err != nil {
+			return err
+		}
+		count.Processed += len(batch)
+		count.Updated += int(res.ModifiedCount)
+		batch = batch[:0]
+		if progress != nil {
+			progress(name, count)
+		}
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		doc := PT(new(T))
+		if err := cursor.Decode(doc); err != nil {
+			return count, err
+		}
+		batch = append(batch, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": doc.GetID()}).
+			SetUpdate(bson.M{"$set": bson.M{"tokens": tokens(doc)}}))
+		if len(batch) == reindexBatchSize {
+			if err := flush(); err != nil {
+				return count, err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return count, err
+	}
+	return count, flush()
+}
+
+// RebuildStoredTokens re-runs the current analyzer over every searchable
+// document so the stored Tokens match what queries are analyzed into.
+func RebuildStoredTokens(ctx context.Context, progress reindexProgress) (map[string]models.ReindexCount, error) {
+	counts := make(map[string]models.ReindexCount, 4)
+	var err error
+
+	if counts["projects"], err = retokenizeCollection(ctx, "projects", projectTokens, progress); err != nil {
+		return counts, err
+	}
+	if counts["experiences"], err = retokenizeCollection(ctx, "experiences", experienceTokens, progress); err != nil {
+		return counts, err
+	}
+	if counts["certifications"], err = retokenizeCollection(ctx, "certifications", certificationTokens, progress); err != nil {
+		return counts, err
+	}
+	if counts["volunteer_experiences"], err = retokenizeCollection(ctx, "volunteer_experiences", volunteerTokens, progress); err != nil {
+		return counts, err
+	}
+	return counts, nil
+}
+
+// saveAnalyzerSignature records which analyzer produced the stored tokens.
+func saveAnalyzerSignature(meta *models.SearchMeta, signature string) error {
+	meta.AnalyzerSignature = signature
+	if meta.ID.IsZero() {
+		return mgm.Coll(meta).Create(meta)
+	}
+	return mgm.Coll(meta).Update(meta)
+}
+
+func loadSearchMeta() (*models.SearchMeta, error) {
+	meta := &models.SearchMeta{}
+	err := mgm.Coll(meta).First(bson.M{}, meta)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// SyncSearchTokens rebuilds the stored tokens when the analyzer configuration
+// or token builders differ from the ones recorded in the search_meta
+// collection.
+func SyncSearchTokens(ctx context.Context, logger *slog.Logger) error {
+	signature := storedTokensSignature()
+
+	meta, err := loadSearchMeta()
+	if err != nil {
+		return err
+	}
+	if meta.AnalyzerSignature == signature {
+		return nil
+	}
+
+	logger.Info("Search analyzer changed, rebuilding stored tokens",
+		"previous", meta.AnalyzerSignature,
+		"current", signature,
+	)
+
+	counts, err := RebuildStoredTokens(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := saveAnalyzerSignature(meta, signature); err != nil {
+		return err
+	}
+
+	InvalidateSearchCache()
+	logger.Info("Stored search tokens rebuilt", "counts", counts)
+	return nil
+}
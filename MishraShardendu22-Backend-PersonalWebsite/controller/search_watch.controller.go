@@ -0,0 +1,286 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MishraShardendu22/models"
+	"github.com/kamva/mgm/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Mongo answers $changeStream on a standalone server with this code.
+const changeStreamNotSupported = 40573
+
+const (
+	watchRetryMin = time.Second
+	watchRetryMax = time.Minute
+)
+
+// searchDecoders turn a changed document into its search document, keyed by
+// collection name.
+var searchDecoders = map[string]func(bson.Raw) (models.SearchDocument, error){
+	mgm.CollName(&models.Project{}): func(raw bson.Raw) (models.SearchDocument, error) {
+		var p models.Project
+		err := bson.Unmarshal(raw, &p)
+		return projectSearchDocument(&p), err
+	},
+	mgm.CollName(&models.Experience{}): func(raw bson.Raw) (models.SearchDocument, error) {
+		var e models.Experience
+		err := bson.Unmarshal(raw, &e)
+		return experienceSearchDocument(&e), err
+	},
+	mgm.CollName(&models.CertificationOrAchievements{}): func(raw bson.Raw) (models.SearchDocument, error) {
+		var c models.CertificationOrAchievements
+		err := bson.Unmarshal(raw, &c)
+		return certificationSearchDocument(&c), err
+	},
+	mgm.CollName(&models.VolunteerExperience{}): func(raw bson.Raw) (models.SearchDocument, error) {
+		var v models.VolunteerExperience
+		err := bson.Unmarshal(raw, &v)
+		return volunteerSearchDocument(&v), err
+	},
+}
+
+// Collections that configure search rather than hold content.
+var (
+	settingsCollection = mgm.CollName(&models.SearchSettings{})
+	synonymsCollection = mgm.CollName(&models.Synonym{})
+)
+
+// watchedCollections lists every collection search depends on, sorted so
+// poll fingerprints compare stably.
+func watchedCollections() []string {
+	colls := make([]string, 0, len(searchDecoders)+2)
+	for coll := range searchDecoders {
+		colls = append(colls, coll)
+	}
+	colls = append(colls, settingsCollection, synonymsCollection)
+	sort.Strings(colls)
+	return colls
+}
+
+type changeEvent struct {
+	FullDocument  bson.Raw `bson:"fullDocument"`
+	OperationType string   `bson:"operationType"`
+	Namespace     struct {
+		Coll string `bson:"coll"`
+	} `bson:"ns"`
+	DocumentKey struct {
+		ID primitive.ObjectID `bson:"_id"`
+	} `bson:"documentKey"`
+	UpdateDescription struct {
+		UpdatedFields bson.Raw `bson:"updatedFields"`
+		RemovedFields []string `bson:"removedFields"`
+	} `bson:"updateDescription"`
+}
+
+// unindexedFields are stored on content documents but never searched, like
+// the repo_stats the enricher refreshes every few hours.
+var unindexedFields = []string{"repo_stats"}
+
+// touchesOnlyUnindexedFields reports whether an update changed nothing but
+// unindexed fields, which leaves the search document as it was.
+func touchesOnlyUnindexedFields(event *changeEvent) bool {
+	elems, err := event.UpdateDescription.UpdatedFields.Elements()
+	if err != nil {
+		return false
+	}
+	fields := event.UpdateDescription.RemovedFields
+	for _, elem := range elems {
+		fields = append(fields, elem.Key())
+	}
+	if len(fields) == 0 {
+		return false
+	}
+	for _, field := range fields {
+		// A nested change shows up as a dotted path.
+		root, _, _ := strings.Cut(field, ".")
+		if !slices.Contains(unindexedFields, root) {
+			return false
+		}
+	}
+	return true
+}
+
+// WatchSearchCollections keeps this replica's search index in step with
+// writes made by any replica. It follows a change stream on the content,
+// settings and synonym collections, and falls back to polling every
+// pollInterval when Mongo is a standalone server without change streams.
+func WatchSearchCollections(ctx context.Context, logger *slog.Logger, pollInterval time.Duration) {
+	watchChanges(ctx, logger, "search",
+		func(ctx context.Context) (bool, error) { return followChangeStream(ctx, logger) },
+		func(ctx context.Context) { pollSearchCollections(ctx, logger, pollInterval) },
+	)
+}
+
+// watchChanges runs follow until ctx ends, reconnecting with backoff when the
+// change stream stops. When Mongo has no change streams it hands over to
+// poll for good.
+func watchChanges(ctx context.Context, logger *slog.Logger, what string,
+	follow func(context.Context) (bool, error), poll func(context.Context)) {
+	backoff := watchRetryMin
+	for ctx.Err() == nil {
+		connected, err := follow(ctx)
+		if connected {
+			backoff = watchRetryMin
+		}
+		var serverErr mongo.ServerError
+		if errors.As(err, &serverErr) && serverErr.HasErrorCode(changeStreamNotSupported) {
+			logger.Info("Change streams unavailable, polling for changes", "watcher", what)
+			poll(ctx)
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		logger.Warn("Change stream stopped, reconnecting", "watcher", what, "error", err, "retry_in", backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, watchRetryMax)
+	}
+}
+
+// followChangeStream applies change events until the stream fails. It
+// reports whether the stream was opened at all.
+func followChangeStream(ctx context.Context, logger *slog.Logger) (bool, error) {
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{"ns.coll": bson.M{"$in": watchedCollections()}}}}}
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+
+	db := mgm.Coll(&models.Project{}).Database()
+	stream, err := db.Watch(ctx, pipeline, opts)
+	if err != nil {
+		return false, err
+	}
+	defer stream.Close(context.Background())
+
+	// Writes may have been missed while disconnected; start from a full rebuild.
+	InvalidateSearchCache()
+	logger.Info("Watching search collections for changes")
+
+	for stream.Next(ctx) {
+		var event changeEvent
+		if err := stream.Decode(&event); err != nil {
+			logger.Warn("Failed to decode search change event", "error", err)
+			continue
+		}
+		if err := applyChangeEvent(&event); err != nil {
+			logger.Warn("Failed to apply search change event",
+				"collection", event.Namespace.Coll,
+				"operation", event.OperationType,
+				"error", err,
+			)
+			InvalidateSearchCache()
+		}
+	}
+	return true, stream.Err()
+}
+
+func applyChangeEvent(event *changeEvent) error {
+	switch event.Namespace.Coll {
+	case settingsCollection:
+		return LoadSearchSettings()
+	case synonymsCollection:
+		return LoadSynonyms()
+	}
+
+	decode, ok := searchDecoders[event.Namespace.Coll]
+	if !ok {
+		return nil
+	}
+
+	switch event.OperationType {
+	case "insert", "update", "replace":
+		if event.OperationType == "update" && touchesOnlyUnindexedFields(event) {
+			return nil
+		}
+		if event.FullDocument == nil {
+			// The document was deleted before the update could be looked up.
+			removeSearchDocument(event.DocumentKey.ID.Hex())
+			return nil
+		}
+		doc, err := decode(event.FullDocument)
+		if err != nil {
+			return err
+		}
+		upsertSearchDocument(doc)
+	case "delete":
+		removeSearchDocument(event.DocumentKey.ID.Hex())
+	default:
+		// drop, rename and invalidate leave nothing to patch.
+		InvalidateSearchCache()
+	}
+	return nil
+}
+
+// pollSearchCollections invalidates the index whenever the document count or
+// the latest updated_at of a watched collection moves. Deletes show up in
+// the count, inserts and updates in updated_at.
+func pollSearchCollections(ctx context.Context, logger *slog.Logger, interval time.Duration) {
+	logger.Info("Polling for search changes", "interval", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last, err := collectionFingerprint(ctx, watchedCollections())
+	if err != nil {
+		logger.Warn("Failed to poll search collections", "error", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current, err := collectionFingerprint(ctx, watchedCollections())
+		if err != nil {
+			logger.Warn("Failed to poll search collections", "error", err)
+			continue
+		}
+		if current == last {
+			continue
+		}
+		// The rebuild on the next search also reloads settings and synonyms.
+		InvalidateSearchCache()
+		last = current
+	}
+}
+
+// collectionFingerprint sums up the document count and latest updated_at of
+// each named collection.
+func collectionFingerprint(ctx context.Context, names []string) (string, error) {
+	db := mgm.Coll(&models.Project{}).Database()
+	fingerprint := ""
+	for _, name := range names {
+		coll := db.Collection(name)
+		count, err := coll.CountDocuments(ctx, bson.M{})
+		if err != nil {
+			return "", err
+		}
+
+		var latest struct {
+			UpdatedAt time.Time `bson:"updated_at"`
+		}
+		opts := options.FindOne().SetSort(bson.M{"updated_at": -1}).SetProjection(bson.M{"updated_at": 1})
+		err = coll.FindOne(ctx, bson.M{}, opts).Decode(&latest)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return "", err
+		}
+		fingerprint += fmt.Sprintf("%s:%d:%d;", name, count, latest.UpdatedAt.UnixNano())
+	}
+	return fingerprint, nil
+}
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestTouchesOnlyUnindexedFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		updated bson.M
+		removed []string
+		want    bool
+	}{
+		{name: "repo stats", updated: bson.M{"repo_stats": bson.M{"stars": 3}}, want: true},
+		{name: "nested repo stats", updated: bson.M{"repo_stats.stars": 3, "repo_stats.fetched_at": 1}, want: true},
+		{name: "removed repo stats", removed: []string{"repo_stats"}, want: true},
+		{name: "title too", updated: bson.M{"repo_stats": bson.M{}, "project_name": "x"}, want: false},
+		{name: "removed description", updated: bson.M{"repo_stats": bson.M{}}, removed: []string{"description"}, want: false},
+		{name: "prefix only", updated: bson.M{"repo_stats_extra": 1}, want: false},
+		{name: "nothing", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var event changeEvent
+			if tt.updated != nil {
+				raw, err := bson.Marshal(tt.updated)
+				if err != nil {
+					t.Fatal(err)
+				}
+				event.UpdateDescription.UpdatedFields = raw
+			}
+			event.UpdateDescription.RemovedFields = tt.removed
+			if got := touchesOnlyUnindexedFields(&event); got != tt.want {
+				t.Errorf("got %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
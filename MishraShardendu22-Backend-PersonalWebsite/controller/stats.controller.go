@@ -1,287 +1,211 @@
 package controller
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"io"
 	"net/http"
 	"os"
 	"sort"
 	"sync"
 	"time"
 
+	"github.com/MishraShardendu22/models"
+	"github.com/MishraShardendu22/outbound"
 	"github.com/gofiber/fiber/v2"
-	"golang.org/x/sync/semaphore"
 )
 
-var httpClient = &http.Client{Timeout: 10 * time.Second}
+// Every call to a stats upstream goes through outboundTransport, which
+// retries, limits and breaks per host; /api/health reports its breakers.
+var (
+	outboundTransport = outbound.NewTransport(outbound.DefaultOptions)
+	httpClient        = &http.Client{Transport: outboundTransport}
+)
+
 var maxWorkers = int64(5)
 
-type RepoInfo struct {
-	Name            string `json:"name"`
-	Description     string `json:"description"`
-	Fork            bool   `json:"fork"`
-	Language        string `json:"language"`
-	LanguagesURL    string `json:"languages_url"`
-	StargazersCount int    `json:"stargazers_count"`
-	HTMLURL         string `json:"html_url"`
-}
+// Forge list endpoints are followed through their Link headers for at most
+// statsPageCap pages, per repository listing and per repo's commits.
+var statsPageCap = 10
 
-func fetchRepos(token, user string) ([]RepoInfo, error) {
-	req, _ := http.NewRequest("GET", "https://api.github.com/users/"+user+"/repos?per_page=100", nil)
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("User-Agent", "fiber-backend")
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+// SetStatsPageCap changes the page cap. It is meant to be called once at
+// startup, before any request is served.
+func SetStatsPageCap(pages int) {
+	statsPageCap = max(pages, 1)
+}
 
-	var repos []RepoInfo
-	body, _ := io.ReadAll(resp.Body)
-	if err := json.Unmarshal(body, &repos); err != nil {
-		return nil, err
-	}
-	return repos, nil
+// SetOutboundOptions replaces the outbound transport. It is meant to be
+// called once at startup, before any request is served.
+func SetOutboundOptions(opts outbound.Options) {
+	outboundTransport = outbound.NewTransport(opts)
+	httpClient = &http.Client{Transport: outboundTransport}
 }
 
-func FetchLeetCodeData(c *fiber.Ctx) error {
-	query := `{
-		matchedUser(username: "ShardenduMishra22") {
-			profile {
-				realName
-				userAvatar
-				ranking
-			}
-			submitStats {
-				acSubmissionNum {
-					difficulty
-					count
-				}
-			}
-		}
-	}`
+const (
+	upstreamGitHub   = "GitHub"
+	upstreamGitLab   = "GitLab"
+	upstreamGitea    = "Gitea"
+	upstreamLeetCode = "LeetCode"
 
-	payload := map[string]string{"query": query}
-	body, _ := json.Marshal(payload)
+	upstreamCodeforces      = "Codeforces"
+	upstreamAtCoder         = "AtCoder"
+	upstreamAtCoderProblems = "AtCoder Problems"
+)
 
-	req, _ := http.NewRequest("POST", "https://leetcode.com/graphql", bytes.NewBuffer(body))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Referer", "https://leetcode.com")
+func FetchLeetCodeData(c *fiber.Ctx) error {
+	return serveStats(c, "leetcode", "LeetCode stats", fetchLeetCodeData)
+}
 
-	resp, err := httpClient.Do(req)
+func fetchLeetCodeData(ctx context.Context) (statsValue, error) {
+	leetcode := newLeetCodeCP(httpClient, defaultLeetCodeURL, currentStatsProfile().LeetCodeUsername)
+	stats, err := leetcode.stats(ctx)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "request_failed"})
+		return statsValue{}, err
 	}
-	defer resp.Body.Close()
-
-	respBody, _ := io.ReadAll(resp.Body)
-	var jsonResponse map[string]interface{}
-	if err := json.Unmarshal(respBody, &jsonResponse); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "invalid_response"})
-	}
-
-	return c.JSON(jsonResponse)
+	return statsValue{data: stats}, nil
 }
 
 func FetchGitHubProfile(c *fiber.Ctx) error {
-	token := os.Getenv("GITHUB_TOKEN")
-	username := "MishraShardendu22"
-	url := "https://api.github.com/users/" + username
-
-	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("User-Agent", "fiber-backend")
+	return serveStats(c, "github-profile", "GitHub profile", fetchGitHubProfile)
+}
 
-	resp, err := httpClient.Do(req)
+// fetchGitHubProfile reads the full profile of the stats profile's GitHub
+// account; /forges covers every account with fewer fields.
+func fetchGitHubProfile(ctx context.Context) (statsValue, error) {
+	github := newGitHubForge(httpClient, defaultForgeBaseURLs[forgeGitHub],
+		currentStatsProfile().GitHubUsername, os.Getenv("GITHUB_TOKEN"))
+	profile, err := github.user(ctx)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "request_failed"})
-	}
-	defer resp.Body.Close()
-
-	body, _ := io.ReadAll(resp.Body)
-	var data map[string]interface{}
-	if err := json.Unmarshal(body, &data); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "invalid_response"})
+		return statsValue{}, err
 	}
-
-	return c.JSON(data)
+	return statsValue{data: profile}, nil
 }
 
 func FetchGitHubCommits(c *fiber.Ctx) error {
-	token := os.Getenv("GITHUB_TOKEN")
-	username := "MishraShardendu22"
-	since := "2024-07-01T00:00:00Z"
+	return serveStats(c, "github-commits", "GitHub commits per day", fetchGitHubCommits)
+}
+
+// fetchGitHubCommits counts commits per day across every stats account, by
+// the author's local date.
+func fetchGitHubCommits(ctx context.Context) (statsValue, error) {
+	since, err := time.Parse(time.DateOnly, currentStatsProfile().CommitsSince)
+	if err != nil {
+		return statsValue{}, err
+	}
 
-	repos, err := fetchRepos(token, username)
+	listings, truncated, err := forgeListings(ctx)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "repo_fetch_failed"})
+		return statsValue{}, err
 	}
 
 	counts := make(map[string]int)
 	mu := sync.Mutex{}
-	sem := semaphore.NewWeighted(maxWorkers)
-	var wg sync.WaitGroup
-
-	for _, repo := range repos {
-		if repo.Fork {
-			continue
+	err = forEachForgeRepo(ctx, listings, func(ctx context.Context, provider ForgeProvider, repo ForgeRepo) error {
+		dates, repoTruncated, err := provider.Commits(ctx, repo, since)
+		if err != nil {
+			return err
 		}
-		wg.Add(1)
-		sem.Acquire(context.Background(), 1)
-		go func(name string) {
-			defer wg.Done()
-			defer sem.Release(1)
 
-			url := "https://api.github.com/repos/" + username + "/" + name + "/commits?since=" + since + "&per_page=100"
-			req, _ := http.NewRequest("GET", url, nil)
-			req.Header.Set("Authorization", "Bearer "+token)
-			req.Header.Set("User-Agent", "fiber-backend")
-			resp, err := httpClient.Do(req)
-			if err != nil {
-				return
-			}
-			defer resp.Body.Close()
-
-			var commits []struct {
-				Commit struct {
-					Author struct{ Date string } `json:"author"`
-				} `json:"commit"`
-			}
-			body, _ := io.ReadAll(resp.Body)
-			if json.Unmarshal(body, &commits) != nil {
-				return
-			}
-			mu.Lock()
-			for _, cm := range commits {
-				day := cm.Commit.Author.Date[:10]
-				counts[day]++
-			}
-			mu.Unlock()
-		}(repo.Name)
+		mu.Lock()
+		defer mu.Unlock()
+		truncated = truncated || repoTruncated
+		for _, date := range dates {
+			counts[date.Format(time.DateOnly)]++
+		}
+		return nil
+	})
+	if err != nil {
+		// A partial count would be cached as the truth; keep the last good one.
+		return statsValue{}, err
 	}
-	wg.Wait()
 
-	var result []map[string]interface{}
+	result := make([]models.CommitDay, 0, len(counts))
 	for date, count := range counts {
-		result = append(result, map[string]interface{}{"date": date, "count": count})
+		result = append(result, models.CommitDay{Date: date, Count: count})
 	}
 	sort.Slice(result, func(i, j int) bool {
-		return result[i]["date"].(string) < result[j]["date"].(string)
+		return result[i].Date < result[j].Date
 	})
 
-	return c.JSON(result)
+	return statsValue{data: result, truncated: truncated}, nil
 }
 
 func FetchGitHubLanguages(c *fiber.Ctx) error {
-	token := os.Getenv("GITHUB_TOKEN")
-	username := "MishraShardendu22"
+	return serveStats(c, "github-languages", "GitHub languages", fetchGitHubLanguages)
+}
 
-	repos, err := fetchRepos(token, username)
+func fetchGitHubLanguages(ctx context.Context) (statsValue, error) {
+	listings, truncated, err := forgeListings(ctx)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "repo_fetch_failed"})
+		return statsValue{}, err
 	}
 
 	langStats := make(map[string]int)
 	mu := sync.Mutex{}
-	sem := semaphore.NewWeighted(maxWorkers)
-	var wg sync.WaitGroup
-
-	for _, repo := range repos {
-		if repo.Fork {
-			continue
+	err = forEachForgeRepo(ctx, listings, func(ctx context.Context, provider ForgeProvider, repo ForgeRepo) error {
+		langs, err := provider.Languages(ctx, repo)
+		if err != nil {
+			return err
 		}
-		wg.Add(1)
-		sem.Acquire(context.Background(), 1)
-		go func(url string) {
-			defer wg.Done()
-			defer sem.Release(1)
-
-			req, _ := http.NewRequest("GET", url, nil)
-			req.Header.Set("Authorization", "Bearer "+token)
-			req.Header.Set("User-Agent", "fiber-backend")
-			resp, err := httpClient.Do(req)
-			if err != nil {
-				return
-			}
-			defer resp.Body.Close()
 
-			var langs map[string]int
-			body, _ := io.ReadAll(resp.Body)
-			if json.Unmarshal(body, &langs) != nil {
-				return
-			}
-			mu.Lock()
-			for lang, bytes := range langs {
-				langStats[lang] += bytes
-			}
-			mu.Unlock()
-		}(repo.LanguagesURL)
+		mu.Lock()
+		defer mu.Unlock()
+		for lang, bytes := range langs {
+			langStats[lang] += bytes
+		}
+		return nil
+	})
+	if err != nil {
+		return statsValue{}, err
 	}
-	wg.Wait()
-	return c.JSON(langStats)
+	return statsValue{data: langStats, truncated: truncated}, nil
 }
 
 func FetchGitHubStars(c *fiber.Ctx) error {
-	token := os.Getenv("GITHUB_TOKEN")
-	username := "MishraShardendu22"
+	return serveStats(c, "github-stars", "GitHub stars", fetchGitHubStars)
+}
 
-	repos, err := fetchRepos(token, username)
+func fetchGitHubStars(ctx context.Context) (statsValue, error) {
+	listings, truncated, err := forgeListings(ctx)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "repo_fetch_failed"})
+		return statsValue{}, err
 	}
 
 	total := 0
-	for _, repo := range repos {
-		total += repo.StargazersCount
+	for _, listing := range listings {
+		for _, repo := range listing.repos {
+			total += repo.Stars
+		}
 	}
-	return c.JSON(fiber.Map{"stars": total})
+	return statsValue{data: models.GitHubStars{Stars: total, Truncated: truncated}, truncated: truncated}, nil
 }
 
 func FetchTopStarredRepos(c *fiber.Ctx) error {
-	token := os.Getenv("GITHUB_TOKEN")
-	username := "MishraShardendu22"
+	return serveStats(c, "github-top-repos", "Top starred repositories", fetchTopStarredRepos)
+}
 
-	repos, err := fetchRepos(token, username)
+func fetchTopStarredRepos(ctx context.Context) (statsValue, error) {
+	listings, truncated, err := forgeListings(ctx)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "repo_fetch_failed"})
+		return statsValue{}, err
+	}
+
+	var repos []models.RepoSummary
+	for _, listing := range listings {
+		for _, r := range listing.repos {
+			repos = append(repos, models.RepoSummary{
+				Forge:           listing.provider.Kind(),
+				Name:            r.Name,
+				HTMLURL:         r.HTMLURL,
+				Description:     r.Description,
+				Language:        r.Language,
+				StargazersCount: r.Stars,
+			})
+		}
 	}
-
-	sort.Slice(repos, func(i, j int) bool {
+	sort.SliceStable(repos, func(i, j int) bool {
 		return repos[i].StargazersCount > repos[j].StargazersCount
 	})
 
-	top := []map[string]interface{}{}
-	for i, r := range repos {
-		if i >= 6 {
-			break
-		}
-		top = append(top, map[string]interface{}{
-			"name":             r.Name,
-			"html_url":         r.HTMLURL,
-			"stargazers_count": r.StargazersCount,
-			"description":      r.Description,
-			"language":         r.Language,
-		})
-	}
-
-	return c.JSON(top)
-}
-
-func FetchContributionCalendar(c *fiber.Ctx) error {
-	username := "MishraShardendu22"
-	url := "https://github-contributions-api.jogruber.de/v4/" + username
-
-	resp, err := httpClient.Get(url)
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "calendar_fetch_failed"})
-	}
-	defer resp.Body.Close()
-
-	body, _ := io.ReadAll(resp.Body)
-	var data map[string]interface{}
-	json.Unmarshal(body, &data)
-
-	return c.JSON(data)
+	top := make([]models.RepoSummary, 0, 6)
+	top = append(top, repos[:min(len(repos), 6)]...)
+	return statsValue{data: top, truncated: truncated}, nil
 }
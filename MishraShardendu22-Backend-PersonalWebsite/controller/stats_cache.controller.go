@@ -0,0 +1,229 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MishraShardendu22/util"
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/sync/singleflight"
+)
+
+// How long each stats response is served without asking upstream again.
+// Slow-moving data is kept longer; per-repo fan-outs are the costliest. A key
+// like repos:<account> takes the TTL of the part before the colon.
+var statsTTLs = map[string]time.Duration{
+	"repos":            15 * time.Minute,
+	"forge-profiles":   10 * time.Minute,
+	"github-profile":   10 * time.Minute,
+	"github-stars":     30 * time.Minute,
+	"github-commits":   time.Hour,
+	"github-languages": 6 * time.Hour,
+	"github-top-repos": 30 * time.Minute,
+	"github-calendar":  time.Hour,
+	"leetcode":         30 * time.Minute,
+	"cp":               30 * time.Minute,
+}
+
+const (
+	// Past its TTL an entry is still served while a refresh runs in the
+	// background. Past TTL plus statsMaxStale the request waits for upstream,
+	// and only gets the old value if upstream fails.
+	statsMaxStale = 24 * time.Hour
+	// A failed refresh is not retried for this long, so an upstream outage
+	// is not hammered by every visitor.
+	statsRetryAfter = 30 * time.Second
+	// statsLoadTimeout bounds a load, per-repo fan-outs included.
+	// statsRequestTimeout bounds how long a request waits for one, leaving
+	// room within the server's write timeout.
+	statsLoadTimeout    = 2 * time.Minute
+	statsRequestTimeout = 20 * time.Second
+)
+
+// X-Cache values.
+const (
+	cacheHit   = "HIT"
+	cacheMiss  = "MISS"
+	cacheStale = "STALE"
+)
+
+// statsValue is one loaded stats payload. truncated is set when paging
+// stopped at the page cap while upstream still had more results, and
+// partial when some of its parts failed to load.
+type statsValue struct {
+	data      any
+	truncated bool
+	partial   bool
+}
+
+type statsEntry struct {
+	value     statsValue
+	fetchedAt time.Time
+	ttl       time.Duration
+}
+
+type statsCache struct {
+	mu         sync.Mutex
+	entries    map[string]*statsEntry
+	refreshing map[string]bool
+	failedAt   map[string]time.Time
+	group      singleflight.Group
+}
+
+var statsResponses = &statsCache{
+	entries:    make(map[string]*statsEntry, len(statsTTLs)),
+	refreshing: make(map[string]bool, len(statsTTLs)),
+	failedAt:   make(map[string]time.Time, len(statsTTLs)),
+}
+
+type freshStatsKey struct{}
+
+// withFreshStats makes gets with the returned context skip stale values:
+// anything past its TTL is loaded again, and a failed load is an error. The
+// loads inherit it, so nested listings are fresh too.
+func withFreshStats(ctx context.Context) context.Context {
+	return context.WithValue(ctx, freshStatsKey{}, true)
+}
+
+func wantsFreshStats(ctx context.Context) bool {
+	fresh, _ := ctx.Value(freshStatsKey{}).(bool)
+	return fresh
+}
+
+// get returns the cached value of key, loading it when missing or too old.
+// Concurrent loads of one key share a single upstream call. The load keeps
+// ctx's values but not its cancellation, since other callers may be waiting
+// on it; a caller whose ctx ends stops waiting, and the load carries on to
+// fill the cache.
+func (sc *statsCache) get(ctx context.Context, key string, load func(context.Context) (statsValue, error)) (statsValue, time.Time, string, error) {
+	sc.mu.Lock()
+	entry, ok := sc.entries[key]
+	sc.mu.Unlock()
+	if ok && wantsFreshStats(ctx) && time.Since(entry.fetchedAt) >= entry.ttl {
+		ok = false
+	}
+
+	if ok {
+		age := time.Since(entry.fetchedAt)
+		if age < entry.ttl {
+			return entry.value, entry.fetchedAt, cacheHit, nil
+		}
+		if age < entry.ttl+statsMaxStale {
+			sc.refresh(ctx, key, load)
+			return entry.value, entry.fetchedAt, cacheStale, nil
+		}
+	}
+
+	var err error
+	loaded := sc.group.DoChan(key, func() (any, error) { return sc.fetch(ctx, key, load) })
+	select {
+	case res := <-loaded:
+		if res.Err == nil {
+			entry = res.Val.(*statsEntry)
+			return entry.value, entry.fetchedAt, cacheMiss, nil
+		}
+		err = res.Err
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if ok {
+		// Keep the last good value rather than fail the request.
+		slog.Warn("Stats refresh failed, serving last good value", "key", key, "error", err)
+		return entry.value, entry.fetchedAt, cacheStale, nil
+	}
+	return statsValue{}, time.Time{}, cacheMiss, err
+}
+
+func (sc *statsCache) fetch(ctx context.Context, key string, load func(context.Context) (statsValue, error)) (any, error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statsLoadTimeout)
+	defer cancel()
+	value, err := load(ctx)
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if err != nil {
+		sc.failedAt[key] = time.Now()
+		return nil, err
+	}
+	prefix, _, _ := strings.Cut(key, ":")
+	ttl := statsTTLs[prefix]
+	if value.partial {
+		// Retried soon, so a part that failed is not missing for a whole TTL.
+		ttl = min(ttl, statsRetryAfter)
+	}
+	entry := &statsEntry{value: value, fetchedAt: time.Now(), ttl: ttl}
+	sc.entries[key] = entry
+	delete(sc.failedAt, key)
+	return entry, nil
+}
+
+// refresh reloads key in the background unless a refresh is already running
+// or the last one failed moments ago.
+func (sc *statsCache) refresh(ctx context.Context, key string, load func(context.Context) (statsValue, error)) {
+	sc.mu.Lock()
+	if sc.refreshing[key] || time.Since(sc.failedAt[key]) < statsRetryAfter {
+		sc.mu.Unlock()
+		return
+	}
+	sc.refreshing[key] = true
+	sc.mu.Unlock()
+
+	go func() {
+		_, err, _ := sc.group.Do(key, func() (any, error) { return sc.fetch(ctx, key, load) })
+		if err != nil {
+			slog.Warn("Background stats refresh failed", "key", key, "error", err)
+		}
+		sc.mu.Lock()
+		delete(sc.refreshing, key)
+		sc.mu.Unlock()
+	}()
+}
+
+// InvalidateStatsCache drops every cached stats response.
+func InvalidateStatsCache() {
+	statsResponses.mu.Lock()
+	clear(statsResponses.entries)
+	clear(statsResponses.failedAt)
+	statsResponses.mu.Unlock()
+}
+
+// serveStats answers from the stats cache and reports how fresh the answer
+// is in the X-Cache and Age headers, and incomplete totals in X-Truncated.
+// Upstream failures are mapped to an error code in the response data. A
+// load that outlasts the request is answered with 504 and keeps running.
+func serveStats(c *fiber.Ctx, key, message string, load func(context.Context) (statsValue, error)) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), statsRequestTimeout)
+	defer cancel()
+
+	value, fetchedAt, status, err := statsResponses.get(ctx, key, load)
+	c.Set("X-Cache", status)
+	if err != nil {
+		var ue *upstreamError
+		if !errors.As(err, &ue) {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return util.ResponseAPI(c, fiber.StatusGatewayTimeout, "Stats are still loading, try again shortly", nil, "")
+			}
+			return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to fetch stats", nil, "")
+		}
+
+		data := fiber.Map{"error": ue.Code}
+		if ue.RetryAfter > 0 {
+			secs := int(ue.RetryAfter.Seconds())
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(secs))
+			data["retry_after"] = secs
+		}
+		return util.ResponseAPI(c, ue.httpStatus(), ue.message(), data, "")
+	}
+
+	c.Set(fiber.HeaderAge, strconv.Itoa(int(time.Since(fetchedAt).Seconds())))
+	if value.truncated {
+		c.Set("X-Truncated", "true")
+	}
+	return util.ResponseAPI(c, fiber.StatusOK, message, value.data, "")
+}
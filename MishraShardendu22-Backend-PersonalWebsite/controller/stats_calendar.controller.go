@@ -0,0 +1,246 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/MishraShardendu22/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Where a contribution calendar was built from.
+const (
+	calendarSourceGraphQL = "graphql"
+	calendarSourceCommits = "commits"
+)
+
+const contributionYearsQuery = `query ($login: String!) {
+	user(login: $login) {
+		contributionsCollection {
+			contributionYears
+		}
+	}
+}`
+
+const contributionCalendarQuery = `query ($login: String!, $from: DateTime!, $to: DateTime!) {
+	user(login: $login) {
+		contributionsCollection(from: $from, to: $to) {
+			contributionCalendar {
+				weeks {
+					contributionDays {
+						date
+						contributionCount
+					}
+				}
+			}
+		}
+	}
+}`
+
+type contributionYearsData struct {
+	User *struct {
+		ContributionsCollection struct {
+			ContributionYears []int `json:"contributionYears"`
+		} `json:"contributionsCollection"`
+	} `json:"user"`
+}
+
+type contributionCalendarData struct {
+	User *struct {
+		ContributionsCollection struct {
+			ContributionCalendar struct {
+				Weeks []struct {
+					ContributionDays []struct {
+						Date              string `json:"date"`
+						ContributionCount int    `json:"contributionCount"`
+					} `json:"contributionDays"`
+				} `json:"weeks"`
+			} `json:"contributionCalendar"`
+		} `json:"contributionsCollection"`
+	} `json:"user"`
+}
+
+var errNoGitHubUser = errors.New("no such user")
+
+// contributions returns the contribution count of every day the account
+// has existed. GitHub serves at most a year per query, so each contribution
+// year is asked for on its own.
+func (g *githubForge) contributions(ctx context.Context, now time.Time) (map[string]int, error) {
+	years, err := githubGraphQL[contributionYearsData](ctx, g, contributionYearsQuery,
+		map[string]any{"login": g.username})
+	if err != nil {
+		return nil, err
+	}
+	if years.User == nil {
+		return nil, &upstreamError{Upstream: g.upstream, Code: upstreamNotFound, Err: errNoGitHubUser}
+	}
+
+	counts := make(map[string]int)
+	for _, year := range years.User.ContributionsCollection.ContributionYears {
+		from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		to := from.AddDate(1, 0, 0).Add(-time.Second)
+		if to.After(now) {
+			to = now
+		}
+		calendar, err := githubGraphQL[contributionCalendarData](ctx, g, contributionCalendarQuery, map[string]any{
+			"login": g.username,
+			"from":  from.Format(time.RFC3339),
+			"to":    to.Format(time.RFC3339),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if calendar.User == nil {
+			return nil, &upstreamError{Upstream: g.upstream, Code: upstreamNotFound, Err: errNoGitHubUser}
+		}
+		for _, week := range calendar.User.ContributionsCollection.ContributionCalendar.Weeks {
+			for _, day := range week.ContributionDays {
+				counts[day.Date] = day.ContributionCount
+			}
+		}
+	}
+	return counts, nil
+}
+
+func FetchContributionCalendar(c *fiber.Ctx) error {
+	return serveStats(c, "github-calendar", "Contribution calendar", fetchContributionCalendar)
+}
+
+// fetchContributionCalendar asks GitHub's GraphQL API for the calendar
+// account's contributions. That API needs a token; without GITHUB_TOKEN the
+// calendar is built from the commits counted by /github/commits instead.
+func fetchContributionCalendar(ctx context.Context) (statsValue, error) {
+	now := time.Now().UTC()
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		value, _, _, err := statsResponses.get(ctx, "github-commits", fetchGitHubCommits)
+		if err != nil {
+			return statsValue{}, err
+		}
+		counts := make(map[string]int)
+		for _, day := range value.data.([]models.CommitDay) {
+			counts[day.Date] = day.Count
+		}
+		calendar := buildContributionCalendar(counts, now, calendarSourceCommits)
+		return statsValue{data: calendar, truncated: value.truncated}, nil
+	}
+
+	github := newGitHubForge(httpClient, defaultForgeBaseURLs[forgeGitHub],
+		currentStatsProfile().CalendarUsername, token)
+	counts, err := github.contributions(ctx, now)
+	if err != nil {
+		return statsValue{}, err
+	}
+	return statsValue{data: buildContributionCalendar(counts, now, calendarSourceGraphQL)}, nil
+}
+
+// buildContributionCalendar lays out counts, keyed by date, from January 1
+// of the first year with a count through today. The days start earlier
+// when the week grid reaches further back.
+func buildContributionCalendar(counts map[string]int, now time.Time, source string) models.ContributionCalendar {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	// A year back from today, rewound to that week's Sunday.
+	gridStart := today.AddDate(0, 0, -364)
+	gridStart = gridStart.AddDate(0, 0, -int(gridStart.Weekday()))
+
+	start := time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	for date := range counts {
+		if t, err := time.Parse(time.DateOnly, date); err == nil && t.Year() < start.Year() {
+			start = time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		}
+	}
+	if gridStart.Before(start) {
+		start = gridStart
+	}
+
+	calendar := models.ContributionCalendar{
+		Total:  make(map[string]int),
+		Source: source,
+	}
+	levels := contributionLevels(counts)
+	var week []models.ContributionDay
+	for d := start; !d.After(today); d = d.AddDate(0, 0, 1) {
+		day := models.ContributionDay{
+			Date:  d.Format(time.DateOnly),
+			Count: counts[d.Format(time.DateOnly)],
+		}
+		day.Level = levels(day.Count)
+		calendar.Contributions = append(calendar.Contributions, day)
+		calendar.Total[strconv.Itoa(d.Year())] += day.Count
+
+		if d.Before(gridStart) {
+			continue
+		}
+		week = append(week, day)
+		if len(week) == 7 || d.Equal(today) {
+			calendar.Weeks = append(calendar.Weeks, week)
+			week = nil
+		}
+	}
+
+	calendar.CurrentStreak, calendar.LongestStreak = contributionStreaks(calendar.Contributions)
+	return calendar
+}
+
+// contributionLevels grades a day from 0 to 4 the way GitHub shades its
+// calendar: 0 without contributions, else by the quartile of the day's count
+// among all days with contributions.
+func contributionLevels(counts map[string]int) func(int) int {
+	var nonZero []int
+	for _, count := range counts {
+		if count > 0 {
+			nonZero = append(nonZero, count)
+		}
+	}
+	slices.Sort(nonZero)
+
+	quartile := func(q int) int {
+		if len(nonZero) == 0 {
+			return 0
+		}
+		return nonZero[(len(nonZero)-1)*q/4]
+	}
+	q1, q2, q3 := quartile(1), quartile(2), quartile(3)
+
+	return func(count int) int {
+		switch {
+		case count <= 0:
+			return 0
+		case count <= q1:
+			return 1
+		case count <= q2:
+			return 2
+		case count <= q3:
+			return 3
+		}
+		return 4
+	}
+}
+
+// contributionStreaks counts consecutive days with contributions. The
+// current streak still holds when today has none yet, as the day is not
+// over.
+func contributionStreaks(days []models.ContributionDay) (current, longest int) {
+	run := 0
+	for _, day := range days {
+		if day.Count > 0 {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+
+	end := len(days) - 1
+	if end >= 0 && days[end].Count == 0 {
+		end--
+	}
+	for i := end; i >= 0 && days[i].Count > 0; i-- {
+		current++
+	}
+	return current, longest
+}
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/MishraShardendu22/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Competitive programming platforms /cp reports on.
+const (
+	cpLeetCode   = "leetcode"
+	cpCodeforces = "codeforces"
+	cpAtCoder    = "atcoder"
+)
+
+// CPProvider reads one competitive programming account and normalizes it.
+type CPProvider interface {
+	Platform() string
+	Profile(ctx context.Context) (models.CPProfile, error)
+}
+
+// cpProviders builds a provider for every platform the stats profile has a
+// handle for, in a fixed order.
+func cpProviders(client *http.Client) []CPProvider {
+	profile := currentStatsProfile()
+	var providers []CPProvider
+	if profile.LeetCodeUsername != "" {
+		providers = append(providers, newLeetCodeCP(client, defaultLeetCodeURL, profile.LeetCodeUsername))
+	}
+	if profile.CodeforcesHandle != "" {
+		providers = append(providers, newCodeforcesCP(client, defaultCodeforcesURL, profile.CodeforcesHandle))
+	}
+	if profile.AtCoderHandle != "" {
+		providers = append(providers, newAtCoderCP(client, defaultAtCoderURL, defaultAtCoderProblemsURL, profile.AtCoderHandle))
+	}
+	return providers
+}
+
+func FetchCPProfiles(c *fiber.Ctx) error {
+	return serveStats(c, "cp", "Competitive programming profiles", fetchCPProfiles)
+}
+
+// fetchCPProfiles caches each platform on its own, so one that is down
+// still contributes its last good profile. A platform with nothing cached
+// is listed with only its error code, and the request fails only when every
+// platform does.
+func fetchCPProfiles(ctx context.Context) (statsValue, error) {
+	return loadCPProfiles(ctx, cpProviders(httpClient))
+}
+
+func loadCPProfiles(ctx context.Context, providers []CPProvider) (statsValue, error) {
+	result := statsValue{}
+	profiles := make([]models.CPProfile, 0, len(providers))
+	var failed []error
+	for _, provider := range providers {
+		value, _, _, err := statsResponses.get(ctx, "cp:"+provider.Platform(), func(ctx context.Context) (statsValue, error) {
+			profile, err := provider.Profile(ctx)
+			return statsValue{data: profile}, err
+		})
+		if err != nil {
+			slog.Warn("Failed to fetch competitive programming profile", "platform", provider.Platform(), "error", err)
+			failed = append(failed, err)
+			result.partial = true
+			profiles = append(profiles, models.CPProfile{Platform: provider.Platform(), Error: cpErrorCode(err)})
+			continue
+		}
+		profiles = append(profiles, value.data.(models.CPProfile))
+	}
+	if len(failed) > 0 && len(failed) == len(providers) {
+		return statsValue{}, failed[0]
+	}
+	result.data = profiles
+	return result, nil
+}
+
+func cpErrorCode(err error) string {
+	var ue *upstreamError
+	switch {
+	case errors.As(err, &ue):
+		return ue.Code
+	case errors.Is(err, context.DeadlineExceeded):
+		return upstreamTimeout
+	default:
+		return upstreamUnavailable
+	}
+}
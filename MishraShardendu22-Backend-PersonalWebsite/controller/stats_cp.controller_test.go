@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MishraShardendu22/models"
+)
+
+// serveFixture answers with a JSON response recorded from the platform's
+// API, stored under testdata/cp.
+func serveFixture(t *testing.T, status int, name string) http.HandlerFunc {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", "cp", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write(body)
+	}
+}
+
+type fakeCP struct {
+	platform string
+	err      error
+}
+
+func (f fakeCP) Platform() string { return f.platform }
+
+func (f fakeCP) Profile(context.Context) (models.CPProfile, error) {
+	return models.CPProfile{Platform: f.platform, Handle: "me"}, f.err
+}
+
+func TestLoadCPProfilesKeepsWorkingPlatforms(t *testing.T) {
+	InvalidateStatsCache()
+	t.Cleanup(InvalidateStatsCache)
+	down := &upstreamError{Upstream: upstreamAtCoder, Code: upstreamUnavailable, Err: errors.New("down")}
+
+	value, err := loadCPProfiles(context.Background(), []CPProvider{
+		fakeCP{platform: "test-up"},
+		fakeCP{platform: "test-down", err: down},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	profiles := value.data.([]models.CPProfile)
+	if len(profiles) != 2 || profiles[0].Handle != "me" || profiles[1].Error != upstreamUnavailable {
+		t.Errorf("unexpected profiles %+v", profiles)
+	}
+	if !value.partial {
+		t.Error("a result missing a platform is not marked partial")
+	}
+
+	_, err = loadCPProfiles(context.Background(), []CPProvider{fakeCP{platform: "test-down", err: down}})
+	if !errors.Is(err, down) {
+		t.Errorf("got %v, want the error when every platform failed", err)
+	}
+}
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/MishraShardendu22/models"
+)
+
+// AtCoder publishes contest history but no solved counts; those come from
+// the community AtCoder Problems API.
+const (
+	defaultAtCoderURL         = "https://atcoder.jp"
+	defaultAtCoderProblemsURL = "https://kenkoooo.com/atcoder/atcoder-api/v3"
+)
+
+type atcoderResult struct {
+	IsRated     bool      `json:"IsRated"`
+	Place       int       `json:"Place"`
+	NewRating   int       `json:"NewRating"`
+	ContestName string    `json:"ContestName"`
+	EndTime     time.Time `json:"EndTime"`
+}
+
+type atcoderACRank struct {
+	Count int `json:"count"`
+}
+
+type atcoderCP struct {
+	client      *http.Client
+	baseURL     string
+	problemsURL string
+	handle      string
+}
+
+func newAtCoderCP(client *http.Client, baseURL, problemsURL, handle string) *atcoderCP {
+	return &atcoderCP{client: client, baseURL: baseURL, problemsURL: problemsURL, handle: handle}
+}
+
+func (a *atcoderCP) Platform() string { return cpAtCoder }
+
+func (a *atcoderCP) get(ctx context.Context, upstream, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	_, err = doUpstream(a.client, upstream, req, out)
+	return err
+}
+
+func (a *atcoderCP) Profile(ctx context.Context) (models.CPProfile, error) {
+	var history []atcoderResult
+	if err := a.get(ctx, upstreamAtCoder, a.baseURL+"/users/"+url.PathEscape(a.handle)+"/history/json", &history); err != nil {
+		return models.CPProfile{}, err
+	}
+	var acRank atcoderACRank
+	if err := a.get(ctx, upstreamAtCoderProblems, a.problemsURL+"/user/ac_rank?user="+url.QueryEscape(a.handle), &acRank); err != nil {
+		return models.CPProfile{}, err
+	}
+
+	profile := models.CPProfile{
+		Platform: cpAtCoder,
+		Handle:   a.handle,
+		Solved:   []models.SolvedCount{{Difficulty: "All", Count: acRank.Count}},
+		Contests: []models.CPContest{},
+	}
+	for _, result := range history {
+		if !result.IsRated {
+			continue
+		}
+		profile.Rating = result.NewRating
+		profile.MaxRating = max(profile.MaxRating, result.NewRating)
+		profile.Contests = append(profile.Contests, models.CPContest{
+			Name:   result.ContestName,
+			Date:   result.EndTime.UTC(),
+			Rank:   result.Place,
+			Rating: result.NewRating,
+		})
+	}
+	if len(profile.Contests) > 0 {
+		profile.Rank = atcoderColor(profile.Rating)
+	}
+	return profile, nil
+}
+
+// atcoderColor is the color AtCoder ranks a rating by.
+func atcoderColor(rating int) string {
+	colors := []string{"gray", "brown", "green", "cyan", "blue", "yellow", "orange"}
+	if i := rating / 400; i < len(colors) {
+		return colors[i]
+	}
+	return "red"
+}
@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestAtCoderProfile(t *testing.T) {
+	srv := newForgeServer(t, map[string]http.HandlerFunc{
+		"GET /users/shardendu/history/json": serveFixture(t, http.StatusOK, "atcoder_history.json"),
+		"GET /problems/user/ac_rank":        serveFixture(t, http.StatusOK, "atcoder_ac_rank.json"),
+	})
+
+	profile, err := newAtCoderCP(srv.Client(), srv.URL, srv.URL+"/problems", "shardendu").Profile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ARC 160 was unrated and is left out.
+	if len(profile.Contests) != 3 {
+		t.Fatalf("got %d contests, want 3", len(profile.Contests))
+	}
+	if profile.Rating != 812 || profile.MaxRating != 845 || profile.Rank != "green" {
+		t.Errorf("unexpected rating %d/%d %q", profile.Rating, profile.MaxRating, profile.Rank)
+	}
+	if profile.Solved[0].Count != 213 {
+		t.Errorf("solved = %v, want 213", profile.Solved)
+	}
+}
+
+func TestAtCoderColor(t *testing.T) {
+	tests := map[int]string{
+		0:    "gray",
+		399:  "gray",
+		400:  "brown",
+		812:  "green",
+		1200: "cyan",
+		1999: "blue",
+		2000: "yellow",
+		2400: "orange",
+		2799: "orange",
+		2800: "red",
+		3500: "red",
+	}
+	for rating, want := range tests {
+		if got := atcoderColor(rating); got != want {
+			t.Errorf("atcoderColor(%d) = %q, want %q", rating, got, want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/MishraShardendu22/models"
+)
+
+const defaultCodeforcesURL = "https://codeforces.com/api"
+
+// codeforcesResponse is the envelope of every Codeforces API method.
+type codeforcesResponse[T any] struct {
+	Status  string `json:"status"`
+	Comment string `json:"comment"`
+	Result  T      `json:"result"`
+}
+
+type codeforcesUser struct {
+	Handle    string `json:"handle"`
+	Rating    int    `json:"rating"`
+	MaxRating int    `json:"maxRating"`
+	Rank      string `json:"rank"`
+}
+
+type codeforcesRatingChange struct {
+	ContestName             string `json:"contestName"`
+	Rank                    int    `json:"rank"`
+	RatingUpdateTimeSeconds int64  `json:"ratingUpdateTimeSeconds"`
+	NewRating               int    `json:"newRating"`
+}
+
+type codeforcesSubmission struct {
+	Verdict string `json:"verdict"`
+	Problem struct {
+		ContestID int    `json:"contestId"`
+		Index     string `json:"index"`
+		Rating    int    `json:"rating"`
+	} `json:"problem"`
+}
+
+type codeforcesCP struct {
+	client  *http.Client
+	baseURL string
+	handle  string
+}
+
+func newCodeforcesCP(client *http.Client, baseURL, handle string) *codeforcesCP {
+	return &codeforcesCP{client: client, baseURL: baseURL, handle: handle}
+}
+
+func (cf *codeforcesCP) Platform() string { return cpCodeforces }
+
+// codeforcesCall runs one API method. Codeforces answers an unknown handle
+// with 400, so that status means not found here.
+func codeforcesCall[T any](ctx context.Context, cf *codeforcesCP, method string) (T, error) {
+	var resp codeforcesResponse[T]
+	req, err := http.NewRequestWithContext(ctx, "GET", cf.baseURL+"/"+method, nil)
+	if err != nil {
+		return resp.Result, err
+	}
+
+	_, err = doUpstream(cf.client, upstreamCodeforces, req, &resp)
+	var ue *upstreamError
+	if errors.As(err, &ue) && ue.Status == http.StatusBadRequest {
+		ue.Code = upstreamNotFound
+	}
+	if err != nil {
+		return resp.Result, err
+	}
+	if resp.Status != "OK" {
+		return resp.Result, &upstreamError{Upstream: upstreamCodeforces, Code: upstreamInvalidResponse,
+			Err: fmt.Errorf("%s: %s", resp.Status, resp.Comment)}
+	}
+	return resp.Result, nil
+}
+
+func (cf *codeforcesCP) Profile(ctx context.Context) (models.CPProfile, error) {
+	handle := url.QueryEscape(cf.handle)
+	users, err := codeforcesCall[[]codeforcesUser](ctx, cf, "user.info?handles="+handle)
+	if err != nil {
+		return models.CPProfile{}, err
+	}
+	if len(users) == 0 {
+		return models.CPProfile{}, &upstreamError{Upstream: upstreamCodeforces, Code: upstreamNotFound,
+			Err: errors.New("no such user")}
+	}
+	changes, err := codeforcesCall[[]codeforcesRatingChange](ctx, cf, "user.rating?handle="+handle)
+	if err != nil {
+		return models.CPProfile{}, err
+	}
+	submissions, err := codeforcesCall[[]codeforcesSubmission](ctx, cf, "user.status?handle="+handle)
+	if err != nil {
+		return models.CPProfile{}, err
+	}
+
+	user := users[0]
+	profile := models.CPProfile{
+		Platform:  cpCodeforces,
+		Handle:    user.Handle,
+		Rating:    user.Rating,
+		MaxRating: user.MaxRating,
+		Rank:      user.Rank,
+		Solved:    codeforcesSolved(submissions),
+		Contests:  make([]models.CPContest, 0, len(changes)),
+	}
+	for _, change := range changes {
+		profile.Contests = append(profile.Contests, models.CPContest{
+			Name:   change.ContestName,
+			Date:   time.Unix(change.RatingUpdateTimeSeconds, 0).UTC(),
+			Rank:   change.Rank,
+			Rating: change.NewRating,
+		})
+	}
+	return profile, nil
+}
+
+// codeforcesSolved counts distinct accepted problems, in total and per
+// problem rating. Problems without a rating are counted as "Unrated".
+func codeforcesSolved(submissions []codeforcesSubmission) []models.SolvedCount {
+	seen := make(map[string]bool)
+	perRating := make(map[int]int)
+	for _, s := range submissions {
+		key := strconv.Itoa(s.Problem.ContestID) + s.Problem.Index
+		if s.Verdict != "OK" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		perRating[s.Problem.Rating]++
+	}
+
+	ratings := make([]int, 0, len(perRating))
+	for rating := range perRating {
+		ratings = append(ratings, rating)
+	}
+	sort.Ints(ratings)
+
+	solved := []models.SolvedCount{{Difficulty: "All", Count: len(seen)}}
+	for _, rating := range ratings {
+		if rating != 0 {
+			solved = append(solved, models.SolvedCount{Difficulty: strconv.Itoa(rating), Count: perRating[rating]})
+		}
+	}
+	if n := perRating[0]; n > 0 {
+		solved = append(solved, models.SolvedCount{Difficulty: "Unrated", Count: n})
+	}
+	return solved
+}
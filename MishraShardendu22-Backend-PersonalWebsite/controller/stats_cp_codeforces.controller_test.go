@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/MishraShardendu22/models"
+)
+
+func TestCodeforcesProfile(t *testing.T) {
+	srv := newForgeServer(t, map[string]http.HandlerFunc{
+		"GET /user.info":   serveFixture(t, http.StatusOK, "codeforces_user_info.json"),
+		"GET /user.rating": serveFixture(t, http.StatusOK, "codeforces_user_rating.json"),
+		"GET /user.status": serveFixture(t, http.StatusOK, "codeforces_user_status.json"),
+	})
+
+	profile, err := newCodeforcesCP(srv.Client(), srv.URL, "shardendu").Profile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Rating != 1523 || profile.MaxRating != 1611 || profile.Rank != "specialist" {
+		t.Errorf("unexpected rating %d/%d %q", profile.Rating, profile.MaxRating, profile.Rank)
+	}
+	if len(profile.Contests) != 3 {
+		t.Fatalf("got %d contests, want 3", len(profile.Contests))
+	}
+	last := profile.Contests[2]
+	if last.Rank != 4410 || last.Rating != 1523 || !last.Date.Equal(time.Unix(1723736100, 0)) {
+		t.Errorf("unexpected contest %+v", last)
+	}
+}
+
+func TestCodeforcesSolved(t *testing.T) {
+	srv := newForgeServer(t, map[string]http.HandlerFunc{
+		"GET /user.status": serveFixture(t, http.StatusOK, "codeforces_user_status.json"),
+	})
+	submissions, err := codeforcesCall[[]codeforcesSubmission](context.Background(),
+		newCodeforcesCP(srv.Client(), srv.URL, "shardendu"), "user.status?handle=shardendu")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2000A is accepted twice, 2000B after a wrong answer, 1950G never, and
+	// 2029H has no rating yet.
+	want := []models.SolvedCount{
+		{Difficulty: "All", Count: 4},
+		{Difficulty: "800", Count: 2},
+		{Difficulty: "1500", Count: 1},
+		{Difficulty: "Unrated", Count: 1},
+	}
+	if got := codeforcesSolved(submissions); !reflect.DeepEqual(got, want) {
+		t.Errorf("solved = %v, want %v", got, want)
+	}
+}
+
+func TestCodeforcesUnknownHandle(t *testing.T) {
+	srv := newForgeServer(t, map[string]http.HandlerFunc{
+		"GET /user.info": serveFixture(t, http.StatusBadRequest, "codeforces_bad_handle.json"),
+	})
+
+	_, err := newCodeforcesCP(srv.Client(), srv.URL, "nobody_here").Profile(context.Background())
+	if ue := upstreamCode(t, err); ue.Code != upstreamNotFound || ue.Upstream != upstreamCodeforces {
+		t.Errorf("got %s from %s, want %s", ue.Code, ue.Upstream, upstreamNotFound)
+	}
+}
@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/MishraShardendu22/models"
+	"github.com/MishraShardendu22/outbound"
+)
+
+const defaultLeetCodeURL = "https://leetcode.com"
+
+const leetCodeQuery = `query ($username: String!) {
+	matchedUser(username: $username) {
+		profile {
+			realName
+			userAvatar
+			ranking
+		}
+		submitStats {
+			acSubmissionNum {
+				difficulty
+				count
+			}
+		}
+	}
+	userContestRanking(username: $username) {
+		rating
+		badge {
+			name
+		}
+	}
+	userContestRankingHistory(username: $username) {
+		attended
+		rating
+		ranking
+		contest {
+			title
+			startTime
+		}
+	}
+}`
+
+type leetCodeResponse struct {
+	Data struct {
+		MatchedUser *struct {
+			Profile struct {
+				RealName   string `json:"realName"`
+				UserAvatar string `json:"userAvatar"`
+				Ranking    int    `json:"ranking"`
+			} `json:"profile"`
+			SubmitStats struct {
+				AcSubmissionNum []models.SolvedCount `json:"acSubmissionNum"`
+			} `json:"submitStats"`
+		} `json:"matchedUser"`
+		// Null for users who never entered a contest.
+		UserContestRanking *struct {
+			Rating float64 `json:"rating"`
+			Badge  *struct {
+				Name string `json:"name"`
+			} `json:"badge"`
+		} `json:"userContestRanking"`
+		UserContestRankingHistory []struct {
+			Attended bool    `json:"attended"`
+			Rating   float64 `json:"rating"`
+			Ranking  int     `json:"ranking"`
+			Contest  struct {
+				Title     string `json:"title"`
+				StartTime int64  `json:"startTime"`
+			} `json:"contest"`
+		} `json:"userContestRankingHistory"`
+	} `json:"data"`
+}
+
+type leetCodeCP struct {
+	client   *http.Client
+	baseURL  string
+	username string
+}
+
+func newLeetCodeCP(client *http.Client, baseURL, username string) *leetCodeCP {
+	return &leetCodeCP{client: client, baseURL: baseURL, username: username}
+}
+
+func (l *leetCodeCP) Platform() string { return cpLeetCode }
+
+// query runs the GraphQL query behind both /leetcode and /cp.
+func (l *leetCodeCP) query(ctx context.Context) (*leetCodeResponse, error) {
+	body, err := json.Marshal(map[string]any{
+		"query":     leetCodeQuery,
+		"variables": map[string]string{"username": l.username},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The query changes nothing, so it is retried like a GET.
+	req, err := http.NewRequestWithContext(outbound.Idempotent(ctx), "POST", l.baseURL+"/graphql", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Referer", defaultLeetCodeURL)
+
+	var resp leetCodeResponse
+	if _, err := doUpstream(l.client, upstreamLeetCode, req, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Data.MatchedUser == nil {
+		// GraphQL reports a missing user as an error next to a null result.
+		return nil, &upstreamError{Upstream: upstreamLeetCode, Code: upstreamNotFound,
+			Err: errors.New("no such user")}
+	}
+	return &resp, nil
+}
+
+func (l *leetCodeCP) stats(ctx context.Context) (models.LeetCodeStats, error) {
+	resp, err := l.query(ctx)
+	if err != nil {
+		return models.LeetCodeStats{}, err
+	}
+	user := resp.Data.MatchedUser
+	return models.LeetCodeStats{
+		Solved:   user.SubmitStats.AcSubmissionNum,
+		Username: l.username,
+		RealName: user.Profile.RealName,
+		Avatar:   user.Profile.UserAvatar,
+		Ranking:  user.Profile.Ranking,
+	}, nil
+}
+
+func (l *leetCodeCP) Profile(ctx context.Context) (models.CPProfile, error) {
+	resp, err := l.query(ctx)
+	if err != nil {
+		return models.CPProfile{}, err
+	}
+
+	profile := models.CPProfile{
+		Platform: cpLeetCode,
+		Handle:   l.username,
+		Solved:   resp.Data.MatchedUser.SubmitStats.AcSubmissionNum,
+		Contests: []models.CPContest{},
+	}
+	if ranking := resp.Data.UserContestRanking; ranking != nil {
+		profile.Rating = int(math.Round(ranking.Rating))
+		if ranking.Badge != nil {
+			profile.Rank = ranking.Badge.Name
+		}
+	}
+	// The history lists every contest held, attended or not.
+	for _, h := range resp.Data.UserContestRankingHistory {
+		if !h.Attended {
+			continue
+		}
+		rating := int(math.Round(h.Rating))
+		profile.MaxRating = max(profile.MaxRating, rating)
+		profile.Contests = append(profile.Contests, models.CPContest{
+			Name:   h.Contest.Title,
+			Date:   time.Unix(h.Contest.StartTime, 0).UTC(),
+			Rank:   h.Ranking,
+			Rating: rating,
+		})
+	}
+	profile.MaxRating = max(profile.MaxRating, profile.Rating)
+	return profile, nil
+}
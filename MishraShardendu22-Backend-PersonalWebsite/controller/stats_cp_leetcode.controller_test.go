@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestLeetCodeProfileSkipsUnattendedContests(t *testing.T) {
+	srv := newForgeServer(t, map[string]http.HandlerFunc{
+		"POST /graphql": serveFixture(t, http.StatusOK, "leetcode_user.json"),
+	})
+
+	profile, err := newLeetCodeCP(srv.Client(), srv.URL, "shardendu").Profile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profile.Contests) != 3 {
+		t.Fatalf("got %d contests, want the 3 attended", len(profile.Contests))
+	}
+	for _, contest := range profile.Contests {
+		if contest.Name == "Weekly Contest 381" || contest.Name == "Weekly Contest 382" {
+			t.Errorf("unattended %s was kept", contest.Name)
+		}
+	}
+	if profile.Rating != 1702 || profile.MaxRating != 1745 || profile.Rank != "" {
+		t.Errorf("unexpected rating %d/%d %q", profile.Rating, profile.MaxRating, profile.Rank)
+	}
+	if profile.Solved[0].Difficulty != "All" || profile.Solved[0].Count != 612 {
+		t.Errorf("solved = %v", profile.Solved)
+	}
+}
+
+func TestLeetCodeUnknownUser(t *testing.T) {
+	srv := newForgeServer(t, map[string]http.HandlerFunc{
+		"POST /graphql": serveFixture(t, http.StatusOK, "leetcode_no_user.json"),
+	})
+
+	_, err := newLeetCodeCP(srv.Client(), srv.URL, "nobody").stats(context.Background())
+	if ue := upstreamCode(t, err); ue.Code != upstreamNotFound {
+		t.Errorf("code = %q, want %q", ue.Code, upstreamNotFound)
+	}
+}
@@ -0,0 +1,384 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/MishraShardendu22/models"
+	"github.com/MishraShardendu22/util"
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/sync/errgroup"
+)
+
+// Forge kinds a stats account can live on. Gitea also covers Forgejo and
+// Codeberg, which serve the same API.
+const (
+	forgeGitHub = "github"
+	forgeGitLab = "gitlab"
+	forgeGitea  = "gitea"
+)
+
+// API roots used when an account leaves base_url empty.
+var defaultForgeBaseURLs = map[string]string{
+	forgeGitHub: "https://api.github.com",
+	forgeGitLab: "https://gitlab.com/api/v4",
+	forgeGitea:  "https://codeberg.org/api/v1",
+}
+
+// Environment variables holding each forge's token when an account leaves
+// token_env empty. Each is only sent to its forge's default API root:
+// accounts elsewhere get no token unless they name their own.
+var defaultForgeTokenEnvs = map[string]string{
+	forgeGitHub: "GITHUB_TOKEN",
+	forgeGitLab: "GITLAB_TOKEN",
+	forgeGitea:  "GITEA_TOKEN",
+}
+
+// Extra accounts fan out to every one of their repositories, so keep the
+// list short.
+const maxForgeAccounts = 10
+
+var (
+	forgeUsernamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,99}$`)
+	// Only token variables may be named, so an account cannot send any other
+	// secret to its base URL.
+	forgeTokenEnvPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*_TOKEN$`)
+)
+
+// ForgeRepo is a repository as every forge reports it. ID is what the
+// forge's per-repository endpoints take: owner/name, or GitLab's project ID.
+type ForgeRepo struct {
+	ID          string
+	Name        string
+	Description string
+	HTMLURL     string
+	Language    string
+	Stars       int
+	Fork        bool
+	// Size in bytes, for forges that report language shares instead of
+	// byte counts.
+	Size int
+}
+
+// ForgeProvider reads the public activity of one account on a code forge.
+// Star totals are summed from Repos. Repos and Commits report truncated when
+// paging stopped at statsPageCap. Repository looks up any repository on the
+// forge by its owner/name path, whoever owns it.
+type ForgeProvider interface {
+	Kind() string
+	Profile(ctx context.Context) (models.ForgeProfile, error)
+	Repos(ctx context.Context) ([]ForgeRepo, bool, error)
+	Languages(ctx context.Context, repo ForgeRepo) (map[string]int, error)
+	Commits(ctx context.Context, repo ForgeRepo, since time.Time) ([]time.Time, bool, error)
+	Repository(ctx context.Context, path string) (models.RepoStats, error)
+}
+
+// forgeClient holds what every provider needs to call its forge: the API
+// root, the account, and how the forge expects its token.
+type forgeClient struct {
+	client     *http.Client
+	upstream   string
+	baseURL    string
+	username   string
+	token      string
+	authHeader string
+	authScheme string
+	accept     string
+}
+
+func (f *forgeClient) newRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if f.token != "" && f.sameOrigin(url) {
+		req.Header.Set(f.authHeader, f.authScheme+f.token)
+	}
+	req.Header.Set("Accept", f.accept)
+	req.Header.Set("User-Agent", "fiber-backend")
+	return req, nil
+}
+
+func (f *forgeClient) get(ctx context.Context, url string, out any) (http.Header, error) {
+	req, err := f.newRequest(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return doUpstream(f.client, f.upstream, req, out)
+}
+
+// sameOrigin reports whether link has the scheme and host of the API root,
+// the only place the account's token may go.
+func (f *forgeClient) sameOrigin(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	base, err := url.Parse(f.baseURL)
+	return err == nil && strings.EqualFold(u.Scheme, base.Scheme) && strings.EqualFold(u.Host, base.Host)
+}
+
+func (f *forgeClient) profile(kind string) models.ForgeProfile {
+	return models.ForgeProfile{Forge: kind, BaseURL: f.baseURL, Username: f.username}
+}
+
+// fetchPages collects a paginated list, following the Link header from url.
+// It reports truncated when it stopped at statsPageCap while more pages
+// remained. A next page on another host than the API root is refused.
+func fetchPages[T any](ctx context.Context, f *forgeClient, url string) ([]T, bool, error) {
+	var items []T
+	for page := 0; url != ""; page++ {
+		if page == statsPageCap {
+			return items, true, nil
+		}
+
+		var pageItems []T
+		header, err := f.get(ctx, url, &pageItems)
+		if err != nil {
+			return nil, false, err
+		}
+		items = append(items, pageItems...)
+		url = util.NextPageURL(header.Get("Link"))
+		if url != "" && !f.sameOrigin(url) {
+			return nil, false, &upstreamError{Upstream: f.upstream, Code: upstreamInvalidResponse,
+				Err: fmt.Errorf("next page %q is not on %s", url, f.baseURL)}
+		}
+	}
+	return items, false, nil
+}
+
+// newForgeProvider builds the provider for account, filling in the default
+// API root of its kind, and its default token variable on that root.
+// Accounts stored before token variables were checked are checked here too.
+func newForgeProvider(account models.ForgeAccount, client *http.Client) (ForgeProvider, error) {
+	if err := validateForgeTokenEnv(account); err != nil {
+		return nil, err
+	}
+	baseURL := forgeBaseURL(account)
+	tokenEnv := account.TokenEnv
+	if tokenEnv == "" && baseURL == defaultForgeBaseURLs[account.Kind] {
+		tokenEnv = defaultForgeTokenEnvs[account.Kind]
+	}
+	token := ""
+	if tokenEnv != "" {
+		token = os.Getenv(tokenEnv)
+	}
+
+	switch account.Kind {
+	case forgeGitHub:
+		return newGitHubForge(client, baseURL, account.Username, token), nil
+	case forgeGitLab:
+		return newGitLabForge(client, baseURL, account.Username, token), nil
+	case forgeGitea:
+		return newGiteaForge(client, baseURL, account.Username, token), nil
+	}
+	return nil, fmt.Errorf("unknown forge %q", account.Kind)
+}
+
+func forgeBaseURL(account models.ForgeAccount) string {
+	if account.BaseURL == "" {
+		return defaultForgeBaseURLs[account.Kind]
+	}
+	return strings.TrimRight(account.BaseURL, "/")
+}
+
+func validateForgeAccount(account models.ForgeAccount) error {
+	if _, ok := defaultForgeBaseURLs[account.Kind]; !ok {
+		return fmt.Errorf("unknown forge kind %q", account.Kind)
+	}
+	pattern := forgeUsernamePattern
+	if account.Kind == forgeGitHub {
+		pattern = githubUsernamePattern
+	}
+	if !pattern.MatchString(account.Username) {
+		return fmt.Errorf("invalid %s username %q", account.Kind, account.Username)
+	}
+	if account.BaseURL != "" {
+		u, err := url.Parse(account.BaseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid base_url %q", account.BaseURL)
+		}
+	}
+	return validateForgeTokenEnv(account)
+}
+
+// validateForgeTokenEnv checks the token variable an account names. A
+// default token may not be pointed at another forge or host.
+func validateForgeTokenEnv(account models.ForgeAccount) error {
+	if account.TokenEnv == "" {
+		return nil
+	}
+	if !forgeTokenEnvPattern.MatchString(account.TokenEnv) {
+		return fmt.Errorf("token_env must name a *_TOKEN variable, got %q", account.TokenEnv)
+	}
+	for kind, env := range defaultForgeTokenEnvs {
+		if account.TokenEnv == env && (account.Kind != kind || forgeBaseURL(account) != defaultForgeBaseURLs[kind]) {
+			return fmt.Errorf("token_env %s is only for %s", env, defaultForgeBaseURLs[kind])
+		}
+	}
+	return nil
+}
+
+func validateForgeAccounts(accounts []models.ForgeAccount) error {
+	if len(accounts) > maxForgeAccounts {
+		return fmt.Errorf("at most %d forge accounts are supported", maxForgeAccounts)
+	}
+	for _, account := range accounts {
+		if err := validateForgeAccount(account); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseForgeAccounts reads FORGE_ACCOUNTS: comma-separated entries of the
+// form kind:username[@base_url][#TOKEN_ENV], for example
+// "gitlab:alice,gitea:bob@https://git.example.com/api/v1#EXAMPLE_TOKEN".
+func ParseForgeAccounts(spec string) ([]models.ForgeAccount, error) {
+	var accounts []models.ForgeAccount
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		var account models.ForgeAccount
+		entry, account.TokenEnv, _ = strings.Cut(entry, "#")
+		kind, rest, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("forge account %q is not kind:username", entry)
+		}
+		account.Kind = strings.ToLower(kind)
+		account.Username, account.BaseURL, _ = strings.Cut(rest, "@")
+
+		if err := validateForgeAccount(account); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	if len(accounts) > maxForgeAccounts {
+		return nil, fmt.Errorf("at most %d forge accounts are supported", maxForgeAccounts)
+	}
+	return accounts, nil
+}
+
+// statsAccounts lists the accounts the stats aggregate over: the GitHub
+// account of the stats profile, then any extra forge accounts.
+func statsAccounts() []models.ForgeAccount {
+	profile := currentStatsProfile()
+	accounts := []models.ForgeAccount{{Kind: forgeGitHub, Username: profile.GitHubUsername}}
+	return append(accounts, profile.Forges...)
+}
+
+// forgeAccountKey identifies an account in cache keys.
+func forgeAccountKey(account models.ForgeAccount) string {
+	return account.Kind + ":" + account.BaseURL + ":" + account.Username
+}
+
+type forgeListing struct {
+	provider  ForgeProvider
+	repos     []ForgeRepo
+	truncated bool
+}
+
+// forgeListings returns the repositories of every stats account. Each
+// account's listing is cached on its own and shared by the endpoints built
+// on it.
+func forgeListings(ctx context.Context) ([]forgeListing, bool, error) {
+	var (
+		listings  []forgeListing
+		truncated bool
+	)
+	for _, account := range statsAccounts() {
+		provider, err := newForgeProvider(account, httpClient)
+		if err != nil {
+			return nil, false, err
+		}
+		value, _, _, err := statsResponses.get(ctx, "repos:"+forgeAccountKey(account), func(ctx context.Context) (statsValue, error) {
+			repos, truncated, err := provider.Repos(ctx)
+			return statsValue{data: repos, truncated: truncated}, err
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		listings = append(listings, forgeListing{
+			provider:  provider,
+			repos:     value.data.([]ForgeRepo),
+			truncated: value.truncated,
+		})
+		truncated = truncated || value.truncated
+	}
+	return listings, truncated, nil
+}
+
+// forEachForgeRepo calls fn for every repository of listings that is not a
+// fork, at most maxWorkers at a time, until ctx is done. The first error
+// that is not skippable cancels the ctx handed to fn and stops new calls,
+// since the caller drops a partial total rather than cache it as the truth;
+// that error is returned.
+func forEachForgeRepo(ctx context.Context, listings []forgeListing, fn func(context.Context, ForgeProvider, ForgeRepo) error) error {
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(int(maxWorkers))
+
+dispatch:
+	for _, listing := range listings {
+		for _, repo := range listing.repos {
+			if repo.Fork {
+				continue
+			}
+			// Go waits for a free worker; by then another may have failed.
+			if gctx.Err() != nil {
+				break dispatch
+			}
+			provider := listing.provider
+			g.Go(func() error {
+				if err := fn(gctx, provider, repo); err != nil && !skippableRepoError(err) {
+					return err
+				}
+				return nil
+			})
+		}
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// skippableRepoError reports failures of a single repository that say
+// nothing about the account: forges answer 409 for an empty repository and
+// 404 for one deleted since the listing.
+func skippableRepoError(err error) bool {
+	var ue *upstreamError
+	return errors.As(err, &ue) && (ue.Status == http.StatusConflict || ue.Status == http.StatusNotFound)
+}
+
+func FetchForgeProfiles(c *fiber.Ctx) error {
+	return serveStats(c, "forge-profiles", "Forge profiles", fetchForgeProfiles)
+}
+
+func fetchForgeProfiles(ctx context.Context) (statsValue, error) {
+	accounts := statsAccounts()
+
+	profiles := make([]models.ForgeProfile, 0, len(accounts))
+	for _, account := range accounts {
+		provider, err := newForgeProvider(account, httpClient)
+		if err != nil {
+			return statsValue{}, err
+		}
+		profile, err := provider.Profile(ctx)
+		if err != nil {
+			return statsValue{}, err
+		}
+		profiles = append(profiles, profile)
+	}
+	return statsValue{data: profiles}, nil
+}
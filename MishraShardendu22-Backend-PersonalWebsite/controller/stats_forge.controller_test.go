@@ -0,0 +1,264 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MishraShardendu22/models"
+)
+
+// newForgeServer serves routes, keyed by "METHOD /path", and fails the test
+// on any other request.
+func newForgeServer(t *testing.T, routes map[string]http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler, ok := routes[r.Method+" "+r.URL.Path]
+		if !ok {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			http.NotFound(w, r)
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func setPageCap(t *testing.T, pages int) {
+	t.Helper()
+	old := statsPageCap
+	statsPageCap = pages
+	t.Cleanup(func() { statsPageCap = old })
+}
+
+func upstreamCode(t *testing.T, err error) *upstreamError {
+	t.Helper()
+	var ue *upstreamError
+	if !errors.As(err, &ue) {
+		t.Fatalf("got error %v, want *upstreamError", err)
+	}
+	return ue
+}
+
+func TestUpstreamStatusCodes(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		header     map[string]string
+		body       string
+		code       string
+		retryAfter bool
+	}{
+		{name: "unauthorized", status: 401, code: upstreamUnauthorized},
+		{name: "forbidden", status: 403, code: upstreamUnauthorized},
+		{name: "github rate limit", status: 403, code: upstreamRateLimited, retryAfter: true,
+			header: map[string]string{"X-RateLimit-Remaining": "0", "Retry-After": "60"}},
+		{name: "too many requests", status: 429, code: upstreamRateLimited, retryAfter: true,
+			header: map[string]string{"Retry-After": "30"}},
+		{name: "not found", status: 404, code: upstreamNotFound},
+		{name: "gateway timeout", status: 504, code: upstreamTimeout},
+		{name: "server error", status: 500, code: upstreamUnavailable},
+		{name: "bad body", status: 200, body: "<html>", code: upstreamInvalidResponse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newForgeServer(t, map[string]http.HandlerFunc{
+				"GET /users/alice": func(w http.ResponseWriter, r *http.Request) {
+					for k, v := range tt.header {
+						w.Header().Set(k, v)
+					}
+					w.WriteHeader(tt.status)
+					w.Write([]byte(tt.body))
+				},
+			})
+
+			_, err := newGiteaForge(srv.Client(), srv.URL, "alice", "").Profile(context.Background())
+			ue := upstreamCode(t, err)
+			if ue.Code != tt.code {
+				t.Errorf("code = %q, want %q", ue.Code, tt.code)
+			}
+			if ue.Upstream != upstreamGitea {
+				t.Errorf("upstream = %q, want %q", ue.Upstream, upstreamGitea)
+			}
+			if (ue.RetryAfter > 0) != tt.retryAfter {
+				t.Errorf("retry after = %s, want set: %t", ue.RetryAfter, tt.retryAfter)
+			}
+		})
+	}
+}
+
+func TestFetchPagesRefusesOtherHosts(t *testing.T) {
+	var leaked bool
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leaked = r.Header.Get("Authorization") != ""
+		writeJSON(w, []githubRepo{})
+	}))
+	defer other.Close()
+
+	srv := newForgeServer(t, map[string]http.HandlerFunc{
+		"GET /users/alice/repos": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Link", "<"+other.URL+"/page2>; rel=\"next\"")
+			writeJSON(w, []githubRepo{{Name: "one", FullName: "alice/one"}})
+		},
+	})
+
+	_, _, err := newGitHubForge(srv.Client(), srv.URL, "alice", "secret").Repos(context.Background())
+	if ue := upstreamCode(t, err); ue.Code != upstreamInvalidResponse {
+		t.Errorf("code = %q, want %q", ue.Code, upstreamInvalidResponse)
+	}
+	if leaked {
+		t.Error("token was sent to another host")
+	}
+}
+
+func TestForEachForgeRepoSkipsMissingRepos(t *testing.T) {
+	statuses := map[string]int{"alice/empty": 409, "alice/gone": 404, "alice/broken": 500}
+	routes := map[string]http.HandlerFunc{}
+	for id, status := range statuses {
+		routes["GET /repos/"+id+"/languages"] = func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}
+	}
+	routes["GET /repos/alice/ok/languages"] = func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]int{"Go": 10})
+	}
+	srv := newForgeServer(t, routes)
+	provider := newGiteaForge(srv.Client(), srv.URL, "alice", "")
+
+	run := func(ids ...string) (map[string]int, error) {
+		listing := forgeListing{provider: provider}
+		for _, id := range ids {
+			listing.repos = append(listing.repos, ForgeRepo{ID: id})
+		}
+		// Forks are never asked for.
+		listing.repos = append(listing.repos, ForgeRepo{ID: "alice/fork", Fork: true})
+
+		total := map[string]int{}
+		var mu sync.Mutex
+		err := forEachForgeRepo(context.Background(), []forgeListing{listing},
+			func(ctx context.Context, p ForgeProvider, repo ForgeRepo) error {
+				langs, err := p.Languages(ctx, repo)
+				mu.Lock()
+				defer mu.Unlock()
+				for lang, n := range langs {
+					total[lang] += n
+				}
+				return err
+			})
+		return total, err
+	}
+
+	total, err := run("alice/ok", "alice/empty", "alice/gone")
+	if err != nil {
+		t.Fatalf("409 and 404 repos should be skipped, got %v", err)
+	}
+	if total["Go"] != 10 {
+		t.Errorf("Go = %d, want 10", total["Go"])
+	}
+
+	if _, err := run("alice/ok", "alice/broken"); upstreamCode(t, err).Status != 500 {
+		t.Errorf("got %v, want the 500", err)
+	}
+}
+
+func TestForEachForgeRepoStopsAfterFailure(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path == "/repos/alice/broken/languages" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		// The rest hang until the failure cancels them.
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+			t.Error("request was not cancelled")
+		}
+	}))
+	defer srv.Close()
+	provider := newGiteaForge(srv.Client(), srv.URL, "alice", "")
+
+	listing := forgeListing{provider: provider, repos: []ForgeRepo{{ID: "alice/broken"}}}
+	for i := range 20 {
+		listing.repos = append(listing.repos, ForgeRepo{ID: "alice/repo" + strconv.Itoa(i)})
+	}
+	err := forEachForgeRepo(context.Background(), []forgeListing{listing},
+		func(ctx context.Context, p ForgeProvider, repo ForgeRepo) error {
+			_, err := p.Languages(ctx, repo)
+			return err
+		})
+	if upstreamCode(t, err).Status != http.StatusInternalServerError {
+		t.Errorf("got %v, want the 500", err)
+	}
+	if n := requests.Load(); n > int32(maxWorkers) {
+		t.Errorf("%d requests after the failure, want at most %d", n, maxWorkers)
+	}
+}
+
+func TestForEachForgeRepoStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	listing := forgeListing{repos: make([]ForgeRepo, maxWorkers+2)}
+	err := forEachForgeRepo(ctx, []forgeListing{listing}, func(context.Context, ForgeProvider, ForgeRepo) error {
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
+
+func TestValidateForgeTokenEnv(t *testing.T) {
+	tests := []struct {
+		account models.ForgeAccount
+		ok      bool
+	}{
+		{models.ForgeAccount{Kind: forgeGitHub, TokenEnv: "GITHUB_TOKEN"}, true},
+		{models.ForgeAccount{Kind: forgeGitHub, BaseURL: "https://api.github.com/", TokenEnv: "GITHUB_TOKEN"}, true},
+		{models.ForgeAccount{Kind: forgeGitHub, BaseURL: "https://ghe.example.com/api/v3", TokenEnv: "GITHUB_TOKEN"}, false},
+		{models.ForgeAccount{Kind: forgeGitea, BaseURL: "https://git.example.com/api/v1", TokenEnv: "GITHUB_TOKEN"}, false},
+		{models.ForgeAccount{Kind: forgeGitLab, TokenEnv: "GITEA_TOKEN"}, false},
+		{models.ForgeAccount{Kind: forgeGitea, BaseURL: "https://git.example.com/api/v1", TokenEnv: "EXAMPLE_TOKEN"}, true},
+		{models.ForgeAccount{Kind: forgeGitea, TokenEnv: "JWT_SECRET"}, false},
+	}
+	for _, tt := range tests {
+		if err := validateForgeTokenEnv(tt.account); (err == nil) != tt.ok {
+			t.Errorf("%+v: got %v, want ok: %t", tt.account, err, tt.ok)
+		}
+	}
+}
+
+func TestDefaultTokenStaysOnDefaultRoot(t *testing.T) {
+	t.Setenv("GITEA_TOKEN", "codeberg-secret")
+
+	provider, err := newForgeProvider(models.ForgeAccount{Kind: forgeGitea, Username: "alice"}, http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := provider.(*giteaForge).token; got != "codeberg-secret" {
+		t.Errorf("token on Codeberg = %q, want the default token", got)
+	}
+
+	provider, err = newForgeProvider(models.ForgeAccount{Kind: forgeGitea, Username: "alice",
+		BaseURL: "https://git.example.com/api/v1"}, http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := provider.(*giteaForge).token; got != "" {
+		t.Errorf("token on another host = %q, want none", got)
+	}
+}
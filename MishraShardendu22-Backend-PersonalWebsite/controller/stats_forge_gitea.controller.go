@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/MishraShardendu22/models"
+)
+
+type giteaUser struct {
+	Login          string `json:"login"`
+	FullName       string `json:"full_name"`
+	AvatarURL      string `json:"avatar_url"`
+	HTMLURL        string `json:"html_url"`
+	FollowersCount int    `json:"followers_count"`
+	FollowingCount int    `json:"following_count"`
+}
+
+type giteaRepo struct {
+	Name        string `json:"name"`
+	FullName    string `json:"full_name"`
+	Description string `json:"description"`
+	Fork        bool   `json:"fork"`
+	Language    string `json:"language"`
+	StarsCount  int    `json:"stars_count"`
+	HTMLURL     string `json:"html_url"`
+}
+
+type giteaRepoDetail struct {
+	FullName        string    `json:"full_name"`
+	StarsCount      int       `json:"stars_count"`
+	ForksCount      int       `json:"forks_count"`
+	OpenIssuesCount int       `json:"open_issues_count"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	Language        string    `json:"language"`
+	Topics          []string  `json:"topics"`
+}
+
+// giteaForge reads Gitea, Forgejo or Codeberg through their /api/v1 root.
+// Their lists are capped at 50 items per page by default.
+type giteaForge struct {
+	forgeClient
+}
+
+func newGiteaForge(client *http.Client, baseURL, username, token string) *giteaForge {
+	return &giteaForge{forgeClient{
+		client:     client,
+		upstream:   upstreamGitea,
+		baseURL:    baseURL,
+		username:   username,
+		token:      token,
+		authHeader: "Authorization",
+		authScheme: "token ",
+		accept:     "application/json",
+	}}
+}
+
+func (g *giteaForge) Kind() string { return forgeGitea }
+
+func (g *giteaForge) Profile(ctx context.Context) (models.ForgeProfile, error) {
+	var user giteaUser
+	if _, err := g.get(ctx, g.baseURL+"/users/"+url.PathEscape(g.username), &user); err != nil {
+		return models.ForgeProfile{}, err
+	}
+	profile := g.profile(forgeGitea)
+	profile.Name = user.FullName
+	profile.AvatarURL = user.AvatarURL
+	profile.HTMLURL = user.HTMLURL
+	profile.Followers = user.FollowersCount
+	profile.Following = user.FollowingCount
+	return profile, nil
+}
+
+func (g *giteaForge) Repos(ctx context.Context) ([]ForgeRepo, bool, error) {
+	repos, truncated, err := fetchPages[giteaRepo](ctx, &g.forgeClient,
+		g.baseURL+"/users/"+url.PathEscape(g.username)+"/repos?limit=50")
+	if err != nil {
+		return nil, false, err
+	}
+
+	result := make([]ForgeRepo, len(repos))
+	for i, r := range repos {
+		result[i] = ForgeRepo{
+			ID:          r.FullName,
+			Name:        r.Name,
+			Description: r.Description,
+			HTMLURL:     r.HTMLURL,
+			Language:    r.Language,
+			Stars:       r.StarsCount,
+			Fork:        r.Fork,
+		}
+	}
+	return result, truncated, nil
+}
+
+func (g *giteaForge) Languages(ctx context.Context, repo ForgeRepo) (map[string]int, error) {
+	var langs map[string]int
+	_, err := g.get(ctx, g.baseURL+"/repos/"+repo.ID+"/languages", &langs)
+	return langs, err
+}
+
+func (g *giteaForge) Commits(ctx context.Context, repo ForgeRepo, since time.Time) ([]time.Time, bool, error) {
+	// Skip the per-commit diff stats and signature checks; only dates are used.
+	commits, truncated, err := fetchPages[commitInfo](ctx, &g.forgeClient,
+		g.baseURL+"/repos/"+repo.ID+"/commits?limit=50&stat=false&verification=false&files=false&since="+
+			url.QueryEscape(since.Format(time.RFC3339)))
+	if err != nil {
+		return nil, false, err
+	}
+	return commitDates(commits), truncated, nil
+}
+
+// Repository reports the last update as the last push; Gitea has no push
+// time of its own.
+func (g *giteaForge) Repository(ctx context.Context, path string) (models.RepoStats, error) {
+	var repo giteaRepoDetail
+	if _, err := g.get(ctx, g.baseURL+"/repos/"+path, &repo); err != nil {
+		return models.RepoStats{}, err
+	}
+	return models.RepoStats{
+		Forge:      forgeGitea,
+		FullName:   repo.FullName,
+		Stars:      repo.StarsCount,
+		Forks:      repo.ForksCount,
+		OpenIssues: repo.OpenIssuesCount,
+		PushedAt:   repo.UpdatedAt,
+		Language:   repo.Language,
+		Topics:     repo.Topics,
+	}, nil
+}
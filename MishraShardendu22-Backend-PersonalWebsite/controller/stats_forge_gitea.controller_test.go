@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestGiteaReposFollowsLinks(t *testing.T) {
+	setPageCap(t, 10)
+	srv := newForgeServer(t, map[string]http.HandlerFunc{
+		"GET /users/alice/repos": func(w http.ResponseWriter, r *http.Request) {
+			if got := r.Header.Get("Authorization"); got != "token secret" {
+				t.Errorf("Authorization = %q", got)
+			}
+			if r.URL.Query().Get("page") == "" {
+				w.Header().Set("Link", `<http://`+r.Host+`/users/alice/repos?limit=50&page=2>; rel="next"`)
+				writeJSON(w, []giteaRepo{{Name: "one", FullName: "alice/one", StarsCount: 1}})
+				return
+			}
+			writeJSON(w, []giteaRepo{{Name: "two", FullName: "alice/two", StarsCount: 2, Fork: true}})
+		},
+	})
+
+	repos, truncated, err := newGiteaForge(srv.Client(), srv.URL, "alice", "secret").Repos(context.Background())
+	if err != nil || truncated {
+		t.Fatalf("err %v, truncated %t", err, truncated)
+	}
+	if len(repos) != 2 || repos[0].ID != "alice/one" || repos[1].Stars != 2 || !repos[1].Fork {
+		t.Errorf("unexpected repos %+v", repos)
+	}
+}
+
+func TestGiteaCommitsTruncatesAtPageCap(t *testing.T) {
+	setPageCap(t, 1)
+	since := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	srv := newForgeServer(t, map[string]http.HandlerFunc{
+		"GET /repos/alice/one/commits": func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			if query.Get("stat") != "false" || query.Get("since") != since.Format(time.RFC3339) {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			page, _ := strconv.Atoi(query.Get("page"))
+			w.Header().Set("Link", `<http://`+r.Host+r.URL.Path+`?page=`+strconv.Itoa(page+2)+`>; rel="next"`)
+			var commits [1]commitInfo
+			commits[0].Commit.Author.Date = since
+			writeJSON(w, commits)
+		},
+	})
+
+	dates, truncated, err := newGiteaForge(srv.Client(), srv.URL, "alice", "").
+		Commits(context.Background(), ForgeRepo{ID: "alice/one"}, since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !truncated || len(dates) != 1 {
+		t.Errorf("got %d dates, truncated %t; want 1, true", len(dates), truncated)
+	}
+}
+
+func TestGiteaRepository(t *testing.T) {
+	updated := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	srv := newForgeServer(t, map[string]http.HandlerFunc{
+		"GET /repos/alice/one": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, giteaRepoDetail{FullName: "alice/one", StarsCount: 3, ForksCount: 1,
+				UpdatedAt: updated, Language: "Go", Topics: []string{"cli"}})
+		},
+	})
+
+	stats, err := newGiteaForge(srv.Client(), srv.URL, "alice", "").Repository(context.Background(), "alice/one")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Forge != forgeGitea || stats.Stars != 3 || !stats.PushedAt.Equal(updated) || stats.Language != "Go" {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/MishraShardendu22/models"
+	"github.com/MishraShardendu22/outbound"
+)
+
+type githubRepo struct {
+	Name            string `json:"name"`
+	FullName        string `json:"full_name"`
+	Description     string `json:"description"`
+	Fork            bool   `json:"fork"`
+	Language        string `json:"language"`
+	StargazersCount int    `json:"stargazers_count"`
+	HTMLURL         string `json:"html_url"`
+}
+
+type githubRepoDetail struct {
+	FullName        string    `json:"full_name"`
+	StargazersCount int       `json:"stargazers_count"`
+	ForksCount      int       `json:"forks_count"`
+	OpenIssuesCount int       `json:"open_issues_count"`
+	PushedAt        time.Time `json:"pushed_at"`
+	Language        string    `json:"language"`
+	Topics          []string  `json:"topics"`
+}
+
+// commitInfo is a commit as GitHub and Gitea list them.
+type commitInfo struct {
+	Commit struct {
+		Author struct {
+			Date time.Time `json:"date"`
+		} `json:"author"`
+	} `json:"commit"`
+}
+
+// githubForge reads github.com, or GitHub Enterprise when baseURL points at
+// its /api/v3 root.
+type githubForge struct {
+	forgeClient
+}
+
+func newGitHubForge(client *http.Client, baseURL, username, token string) *githubForge {
+	return &githubForge{forgeClient{
+		client:     client,
+		upstream:   upstreamGitHub,
+		baseURL:    baseURL,
+		username:   username,
+		token:      token,
+		authHeader: "Authorization",
+		authScheme: "Bearer ",
+		accept:     "application/vnd.github+json",
+	}}
+}
+
+func (g *githubForge) Kind() string { return forgeGitHub }
+
+// user returns the full GitHub profile, which has more fields than the
+// common ForgeProfile.
+func (g *githubForge) user(ctx context.Context) (models.GitHubProfile, error) {
+	var profile models.GitHubProfile
+	_, err := g.get(ctx, g.baseURL+"/users/"+url.PathEscape(g.username), &profile)
+	return profile, err
+}
+
+func (g *githubForge) Profile(ctx context.Context) (models.ForgeProfile, error) {
+	user, err := g.user(ctx)
+	if err != nil {
+		return models.ForgeProfile{}, err
+	}
+	profile := g.profile(forgeGitHub)
+	profile.Name = user.Name
+	profile.AvatarURL = user.AvatarURL
+	profile.HTMLURL = user.HTMLURL
+	profile.Followers = user.Followers
+	profile.Following = user.Following
+	return profile, nil
+}
+
+func (g *githubForge) Repos(ctx context.Context) ([]ForgeRepo, bool, error) {
+	repos, truncated, err := fetchPages[githubRepo](ctx, &g.forgeClient,
+		g.baseURL+"/users/"+url.PathEscape(g.username)+"/repos?per_page=100")
+	if err != nil {
+		return nil, false, err
+	}
+
+	result := make([]ForgeRepo, len(repos))
+	for i, r := range repos {
+		result[i] = ForgeRepo{
+			ID:          r.FullName,
+			Name:        r.Name,
+			Description: r.Description,
+			HTMLURL:     r.HTMLURL,
+			Language:    r.Language,
+			Stars:       r.StargazersCount,
+			Fork:        r.Fork,
+		}
+	}
+	return result, truncated, nil
+}
+
+func (g *githubForge) Languages(ctx context.Context, repo ForgeRepo) (map[string]int, error) {
+	var langs map[string]int
+	_, err := g.get(ctx, g.baseURL+"/repos/"+repo.ID+"/languages", &langs)
+	return langs, err
+}
+
+func (g *githubForge) Commits(ctx context.Context, repo ForgeRepo, since time.Time) ([]time.Time, bool, error) {
+	commits, truncated, err := fetchPages[commitInfo](ctx, &g.forgeClient,
+		g.baseURL+"/repos/"+repo.ID+"/commits?per_page=100&since="+url.QueryEscape(since.Format(time.RFC3339)))
+	if err != nil {
+		return nil, false, err
+	}
+	return commitDates(commits), truncated, nil
+}
+
+// Repository counts open pull requests as open issues, as GitHub does.
+func (g *githubForge) Repository(ctx context.Context, path string) (models.RepoStats, error) {
+	var repo githubRepoDetail
+	if _, err := g.get(ctx, g.baseURL+"/repos/"+path, &repo); err != nil {
+		return models.RepoStats{}, err
+	}
+	return models.RepoStats{
+		Forge:      forgeGitHub,
+		FullName:   repo.FullName,
+		Stars:      repo.StargazersCount,
+		Forks:      repo.ForksCount,
+		OpenIssues: repo.OpenIssuesCount,
+		PushedAt:   repo.PushedAt,
+		Language:   repo.Language,
+		Topics:     repo.Topics,
+	}, nil
+}
+
+func commitDates(commits []commitInfo) []time.Time {
+	dates := make([]time.Time, 0, len(commits))
+	for _, cm := range commits {
+		if !cm.Commit.Author.Date.IsZero() {
+			dates = append(dates, cm.Commit.Author.Date)
+		}
+	}
+	return dates
+}
+
+type githubGraphQLResponse[T any] struct {
+	Data   T `json:"data"`
+	Errors []struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// githubGraphQL runs a GraphQL query, which GitHub only answers with a
+// token. Errors next to the data fail the call.
+func githubGraphQL[T any](ctx context.Context, g *githubForge, query string, variables map[string]any) (T, error) {
+	var resp githubGraphQLResponse[T]
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return resp.Data, err
+	}
+	// Queries change nothing, so they are retried like GETs.
+	req, err := g.newRequest(outbound.Idempotent(ctx), "POST", g.baseURL+"/graphql", bytes.NewReader(body))
+	if err != nil {
+		return resp.Data, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if _, err := doUpstream(g.client, g.upstream, req, &resp); err != nil {
+		return resp.Data, err
+	}
+	if len(resp.Errors) > 0 {
+		code := upstreamInvalidResponse
+		if resp.Errors[0].Type == "NOT_FOUND" {
+			code = upstreamNotFound
+		}
+		return resp.Data, &upstreamError{Upstream: g.upstream, Code: code, Err: errors.New(resp.Errors[0].Message)}
+	}
+	return resp.Data, nil
+}
@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// githubRepoPages serves three pages of one repository each, linked by
+// rel="next" the way GitHub does.
+func githubRepoPages(t *testing.T, token string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer "+token {
+			t.Errorf("Authorization = %q", got)
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		page = max(page, 1)
+		if page < 3 {
+			next := "http://" + r.Host + r.URL.Path + "?per_page=100&page=" + strconv.Itoa(page+1)
+			w.Header().Set("Link", `<`+next+`>; rel="next", <`+next+`>; rel="last"`)
+		}
+		writeJSON(w, []githubRepo{{
+			Name:            "repo" + strconv.Itoa(page),
+			FullName:        "alice/repo" + strconv.Itoa(page),
+			StargazersCount: page,
+			Fork:            page == 2,
+		}})
+	}
+}
+
+func TestGitHubReposFollowsLinks(t *testing.T) {
+	setPageCap(t, 10)
+	srv := newForgeServer(t, map[string]http.HandlerFunc{
+		"GET /users/alice/repos": githubRepoPages(t, "secret"),
+	})
+
+	repos, truncated, err := newGitHubForge(srv.Client(), srv.URL, "alice", "secret").Repos(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if truncated {
+		t.Error("truncated with every page read")
+	}
+	if len(repos) != 3 {
+		t.Fatalf("got %d repos, want 3", len(repos))
+	}
+	if repos[1].ID != "alice/repo2" || !repos[1].Fork || repos[2].Stars != 3 {
+		t.Errorf("unexpected repos %+v", repos)
+	}
+}
+
+func TestGitHubReposTruncatesAtPageCap(t *testing.T) {
+	setPageCap(t, 2)
+	srv := newForgeServer(t, map[string]http.HandlerFunc{
+		"GET /users/alice/repos": githubRepoPages(t, "secret"),
+	})
+
+	repos, truncated, err := newGitHubForge(srv.Client(), srv.URL, "alice", "secret").Repos(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !truncated || len(repos) != 2 {
+		t.Errorf("got %d repos, truncated %t; want 2, true", len(repos), truncated)
+	}
+}
+
+func TestGitHubCommits(t *testing.T) {
+	since := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	srv := newForgeServer(t, map[string]http.HandlerFunc{
+		"GET /repos/alice/one/commits": func(w http.ResponseWriter, r *http.Request) {
+			if got := r.URL.Query().Get("since"); got != since.Format(time.RFC3339) {
+				t.Errorf("since = %q", got)
+			}
+			var commits [2]commitInfo
+			commits[0].Commit.Author.Date = since.Add(time.Hour)
+			writeJSON(w, commits)
+		},
+	})
+
+	dates, _, err := newGitHubForge(srv.Client(), srv.URL, "alice", "").
+		Commits(context.Background(), ForgeRepo{ID: "alice/one"}, since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The commit without an author date is left out.
+	if len(dates) != 1 || !dates[0].Equal(since.Add(time.Hour)) {
+		t.Errorf("dates = %v", dates)
+	}
+}
+
+func TestGitHubGraphQLNotFound(t *testing.T) {
+	srv := newForgeServer(t, map[string]http.HandlerFunc{
+		"POST /graphql": func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"data":{"user":null},"errors":[{"type":"NOT_FOUND","message":"no user"}]}`))
+		},
+	})
+
+	_, err := newGitHubForge(srv.Client(), srv.URL, "alice", "secret").contributions(context.Background(), time.Now())
+	if ue := upstreamCode(t, err); ue.Code != upstreamNotFound {
+		t.Errorf("code = %q, want %q", ue.Code, upstreamNotFound)
+	}
+}
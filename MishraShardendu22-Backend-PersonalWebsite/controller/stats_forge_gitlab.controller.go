@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/MishraShardendu22/models"
+)
+
+type gitlabUser struct {
+	ID        int    `json:"id"`
+	Username  string `json:"username"`
+	Name      string `json:"name"`
+	AvatarURL string `json:"avatar_url"`
+	WebURL    string `json:"web_url"`
+	Followers int    `json:"followers"`
+	Following int    `json:"following"`
+}
+
+type gitlabProject struct {
+	ID                int    `json:"id"`
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	WebURL            string `json:"web_url"`
+	StarCount         int    `json:"star_count"`
+	ForkedFromProject *struct {
+		ID int `json:"id"`
+	} `json:"forked_from_project"`
+	Statistics *struct {
+		RepositorySize int `json:"repository_size"`
+	} `json:"statistics"`
+}
+
+type gitlabProjectDetail struct {
+	ID                int       `json:"id"`
+	PathWithNamespace string    `json:"path_with_namespace"`
+	StarCount         int       `json:"star_count"`
+	ForksCount        int       `json:"forks_count"`
+	OpenIssuesCount   int       `json:"open_issues_count"`
+	LastActivityAt    time.Time `json:"last_activity_at"`
+	Topics            []string  `json:"topics"`
+}
+
+type gitlabCommit struct {
+	AuthoredDate time.Time `json:"authored_date"`
+}
+
+// gitlabForge reads gitlab.com or a self-managed GitLab through its /api/v4
+// root.
+type gitlabForge struct {
+	forgeClient
+}
+
+func newGitLabForge(client *http.Client, baseURL, username, token string) *gitlabForge {
+	return &gitlabForge{forgeClient{
+		client:     client,
+		upstream:   upstreamGitLab,
+		baseURL:    baseURL,
+		username:   username,
+		token:      token,
+		authHeader: "PRIVATE-TOKEN",
+		accept:     "application/json",
+	}}
+}
+
+func (g *gitlabForge) Kind() string { return forgeGitLab }
+
+func (g *gitlabForge) Profile(ctx context.Context) (models.ForgeProfile, error) {
+	// Follower counts are only on the user itself, which is looked up by ID.
+	var matches []gitlabUser
+	if _, err := g.get(ctx, g.baseURL+"/users?username="+url.QueryEscape(g.username), &matches); err != nil {
+		return models.ForgeProfile{}, err
+	}
+	if len(matches) == 0 {
+		return models.ForgeProfile{}, &upstreamError{Upstream: upstreamGitLab, Code: upstreamNotFound,
+			Err: errors.New("no such user")}
+	}
+
+	var user gitlabUser
+	if _, err := g.get(ctx, g.baseURL+"/users/"+strconv.Itoa(matches[0].ID), &user); err != nil {
+		return models.ForgeProfile{}, err
+	}
+	profile := g.profile(forgeGitLab)
+	profile.Name = user.Name
+	profile.AvatarURL = user.AvatarURL
+	profile.HTMLURL = user.WebURL
+	profile.Followers = user.Followers
+	profile.Following = user.Following
+	return profile, nil
+}
+
+func (g *gitlabForge) Repos(ctx context.Context) ([]ForgeRepo, bool, error) {
+	projects, truncated, err := fetchPages[gitlabProject](ctx, &g.forgeClient,
+		g.baseURL+"/users/"+url.PathEscape(g.username)+"/projects?per_page=100&statistics=true")
+	if err != nil {
+		return nil, false, err
+	}
+
+	result := make([]ForgeRepo, len(projects))
+	for i, p := range projects {
+		result[i] = ForgeRepo{
+			ID:          strconv.Itoa(p.ID),
+			Name:        p.Name,
+			Description: p.Description,
+			HTMLURL:     p.WebURL,
+			Stars:       p.StarCount,
+			Fork:        p.ForkedFromProject != nil,
+		}
+		if p.Statistics != nil {
+			result[i].Size = p.Statistics.RepositorySize
+		}
+	}
+	return result, truncated, nil
+}
+
+// Languages turns GitLab's language percentages into bytes with the
+// project's repository size. GitLab only discloses that size to members, so
+// other projects add nothing rather than a made-up byte count.
+func (g *gitlabForge) Languages(ctx context.Context, repo ForgeRepo) (map[string]int, error) {
+	if repo.Size <= 0 {
+		return nil, nil
+	}
+	var shares map[string]float64
+	if _, err := g.get(ctx, g.baseURL+"/projects/"+repo.ID+"/languages", &shares); err != nil {
+		return nil, err
+	}
+
+	langs := make(map[string]int, len(shares))
+	for lang, percent := range shares {
+		langs[lang] = int(percent / 100 * float64(repo.Size))
+	}
+	return langs, nil
+}
+
+func (g *gitlabForge) Commits(ctx context.Context, repo ForgeRepo, since time.Time) ([]time.Time, bool, error) {
+	commits, truncated, err := fetchPages[gitlabCommit](ctx, &g.forgeClient,
+		g.baseURL+"/projects/"+repo.ID+"/repository/commits?per_page=100&since="+url.QueryEscape(since.Format(time.RFC3339)))
+	if err != nil {
+		return nil, false, err
+	}
+
+	dates := make([]time.Time, 0, len(commits))
+	for _, cm := range commits {
+		if !cm.AuthoredDate.IsZero() {
+			dates = append(dates, cm.AuthoredDate)
+		}
+	}
+	return dates, truncated, nil
+}
+
+// Repository takes the project's largest language as its primary one and
+// its last activity as the last push; GitLab reports neither directly.
+func (g *gitlabForge) Repository(ctx context.Context, path string) (models.RepoStats, error) {
+	var project gitlabProjectDetail
+	if _, err := g.get(ctx, g.baseURL+"/projects/"+url.PathEscape(path), &project); err != nil {
+		return models.RepoStats{}, err
+	}
+	var shares map[string]float64
+	if _, err := g.get(ctx, g.baseURL+"/projects/"+strconv.Itoa(project.ID)+"/languages", &shares); err != nil {
+		return models.RepoStats{}, err
+	}
+
+	language, largest := "", 0.0
+	for lang, percent := range shares {
+		if percent > largest || (percent == largest && lang < language) {
+			language, largest = lang, percent
+		}
+	}
+	return models.RepoStats{
+		Forge:      forgeGitLab,
+		FullName:   project.PathWithNamespace,
+		Stars:      project.StarCount,
+		Forks:      project.ForksCount,
+		OpenIssues: project.OpenIssuesCount,
+		PushedAt:   project.LastActivityAt,
+		Language:   language,
+		Topics:     project.Topics,
+	}, nil
+}
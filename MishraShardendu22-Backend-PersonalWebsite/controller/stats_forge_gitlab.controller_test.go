@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGitLabProfileLooksUpUserByID(t *testing.T) {
+	srv := newForgeServer(t, map[string]http.HandlerFunc{
+		"GET /users": func(w http.ResponseWriter, r *http.Request) {
+			if got := r.Header.Get("PRIVATE-TOKEN"); got != "secret" {
+				t.Errorf("PRIVATE-TOKEN = %q", got)
+			}
+			if got := r.URL.Query().Get("username"); got != "alice" {
+				t.Errorf("username = %q", got)
+			}
+			// The search result has no follower counts.
+			writeJSON(w, []gitlabUser{{ID: 42, Username: "alice"}})
+		},
+		"GET /users/42": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, gitlabUser{ID: 42, Username: "alice", Name: "Alice", Followers: 7, Following: 3})
+		},
+	})
+
+	profile, err := newGitLabForge(srv.Client(), srv.URL, "alice", "secret").Profile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Forge != forgeGitLab || profile.Name != "Alice" || profile.Followers != 7 || profile.Following != 3 {
+		t.Errorf("unexpected profile %+v", profile)
+	}
+}
+
+func TestGitLabProfileUnknownUser(t *testing.T) {
+	srv := newForgeServer(t, map[string]http.HandlerFunc{
+		"GET /users": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, []gitlabUser{})
+		},
+	})
+
+	_, err := newGitLabForge(srv.Client(), srv.URL, "nobody", "").Profile(context.Background())
+	if ue := upstreamCode(t, err); ue.Code != upstreamNotFound {
+		t.Errorf("code = %q, want %q", ue.Code, upstreamNotFound)
+	}
+}
+
+func TestGitLabRepos(t *testing.T) {
+	srv := newForgeServer(t, map[string]http.HandlerFunc{
+		"GET /users/alice/projects": func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`[
+				{"id": 1, "name": "own", "star_count": 4, "statistics": {"repository_size": 2000}},
+				{"id": 2, "name": "forked", "forked_from_project": {"id": 9}}
+			]`))
+		},
+	})
+
+	repos, truncated, err := newGitLabForge(srv.Client(), srv.URL, "alice", "").Repos(context.Background())
+	if err != nil || truncated {
+		t.Fatalf("err %v, truncated %t", err, truncated)
+	}
+	if len(repos) != 2 {
+		t.Fatalf("got %d repos, want 2", len(repos))
+	}
+	if repos[0].ID != "1" || repos[0].Stars != 4 || repos[0].Size != 2000 || repos[0].Fork {
+		t.Errorf("unexpected repo %+v", repos[0])
+	}
+	if !repos[1].Fork || repos[1].Size != 0 {
+		t.Errorf("unexpected repo %+v", repos[1])
+	}
+}
+
+func TestGitLabLanguagesConvertsShares(t *testing.T) {
+	srv := newForgeServer(t, map[string]http.HandlerFunc{
+		"GET /projects/1/languages": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, map[string]float64{"Go": 75, "Shell": 25})
+		},
+	})
+	gitlab := newGitLabForge(srv.Client(), srv.URL, "alice", "")
+
+	langs, err := gitlab.Languages(context.Background(), ForgeRepo{ID: "1", Size: 2000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if langs["Go"] != 1500 || langs["Shell"] != 500 {
+		t.Errorf("langs = %v, want Go 1500 and Shell 500", langs)
+	}
+
+	// Without a disclosed size, nothing is counted and nothing is asked.
+	langs, err = gitlab.Languages(context.Background(), ForgeRepo{ID: "2"})
+	if err != nil || len(langs) != 0 {
+		t.Errorf("got %v, %v; want no languages", langs, err)
+	}
+}
+
+func TestGitLabRepositoryEscapesPath(t *testing.T) {
+	// The project path is one escaped segment, which the route table cannot
+	// match on.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.RawPath {
+		case "/projects/group%2Fsub%2Fname":
+			w.Write([]byte(`{"id": 5, "path_with_namespace": "group/sub/name", "star_count": 2}`))
+		default:
+			if r.URL.Path != "/projects/5/languages" {
+				t.Errorf("unexpected request %s", r.URL)
+			}
+			writeJSON(w, map[string]float64{"Go": 60, "C": 40})
+		}
+	}))
+	defer srv.Close()
+
+	stats, err := newGitLabForge(srv.Client(), srv.URL, "alice", "").Repository(context.Background(), "group/sub/name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.FullName != "group/sub/name" || stats.Stars != 2 || stats.Language != "Go" {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,225 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MishraShardendu22/models"
+	"github.com/MishraShardendu22/util"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kamva/mgm/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultStatsRange = "1y"
+
+// StartStatsCollector stores a stats snapshot every interval until ctx is
+// done. Time is cut into interval-long buckets, and each bucket gets one
+// snapshot however many replicas collect. Snapshot values are loaded fresh
+// rather than served stale from the stats cache, so each is the bucket's
+// own.
+func StartStatsCollector(ctx context.Context, logger *slog.Logger, interval time.Duration) {
+	if err := ensureStatsSnapshotIndex(ctx); err != nil {
+		logger.Warn("Failed to create stats snapshot index", "error", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := collectStatsSnapshot(ctx, interval); err != nil {
+			logger.Warn("Failed to collect stats snapshot", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ensureStatsSnapshotIndex makes buckets unique, so replicas that snapshot
+// at once cannot both insert. Snapshots from before buckets existed have
+// none and are left out of the index.
+func ensureStatsSnapshotIndex(ctx context.Context) error {
+	_, err := mgm.Coll(&models.StatsSnapshot{}).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "bucket", Value: 1}},
+		Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"bucket": bson.M{"$type": "date"}}),
+	})
+	return err
+}
+
+func collectStatsSnapshot(ctx context.Context, interval time.Duration) error {
+	coll := mgm.Coll(&models.StatsSnapshot{})
+	now := time.Now().UTC()
+	bucket := now.Truncate(interval)
+
+	// Skip the upstream calls when another replica already filled the bucket.
+	count, err := coll.CountDocuments(ctx, bson.M{"bucket": bucket})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	snapshot := takeStatsSnapshot(withFreshStats(ctx))
+	if snapshot.Followers == nil && snapshot.Stars == nil &&
+		snapshot.Languages == nil && snapshot.LeetCodeSolved == nil {
+		return errors.New("no stats could be fetched")
+	}
+	snapshot.ID = primitive.NewObjectID()
+	snapshot.CreatedAt = now
+	snapshot.UpdatedAt = now
+	snapshot.Bucket = bucket
+
+	// Only the first writer of a bucket inserts; the unique index turns a
+	// simultaneous second insert into a duplicate key error.
+	_, err = coll.UpdateOne(ctx, bson.M{"bucket": bucket},
+		bson.M{"$setOnInsert": snapshot}, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+// takeStatsSnapshot gathers every metric it can; one failing upstream does
+// not hold back the others.
+func takeStatsSnapshot(ctx context.Context) *models.StatsSnapshot {
+	snapshot := &models.StatsSnapshot{}
+
+	if value, _, _, err := statsResponses.get(ctx, "github-profile", fetchGitHubProfile); err == nil {
+		followers := value.data.(models.GitHubProfile).Followers
+		snapshot.Followers = &followers
+	}
+
+	if value, _, _, err := statsResponses.get(ctx, "github-stars", fetchGitHubStars); err == nil {
+		stars := value.data.(models.GitHubStars).Stars
+		snapshot.Stars = &stars
+		snapshot.Truncated = snapshot.Truncated || value.truncated
+	}
+
+	if value, _, _, err := statsResponses.get(ctx, "github-languages", fetchGitHubLanguages); err == nil {
+		if languages := value.data.(map[string]int); len(languages) > 0 {
+			snapshot.Languages = languages
+		}
+		snapshot.Truncated = snapshot.Truncated || value.truncated
+	}
+
+	if value, _, _, err := statsResponses.get(ctx, "leetcode", fetchLeetCodeData); err == nil {
+		leetcode := value.data.(models.LeetCodeStats)
+		if len(leetcode.Solved) > 0 {
+			solved := make(map[string]int, len(leetcode.Solved))
+			for _, n := range leetcode.Solved {
+				solved[strings.ToLower(n.Difficulty)] = n.Count
+			}
+			snapshot.LeetCodeSolved = solved
+		}
+	}
+
+	return snapshot
+}
+
+// statsMetricField maps a metric name to its snapshot field: followers,
+// stars, leetcode_<difficulty> (all, easy, medium, hard) or
+// language:<name>.
+func statsMetricField(metric string) (string, error) {
+	switch {
+	case metric == "followers" || metric == "stars":
+		return metric, nil
+	case strings.HasPrefix(metric, "leetcode_"):
+		difficulty := strings.TrimPrefix(metric, "leetcode_")
+		switch difficulty {
+		case "all", "easy", "medium", "hard":
+			return "leetcode_solved." + difficulty, nil
+		}
+	case strings.HasPrefix(metric, "language:"):
+		language := strings.TrimPrefix(metric, "language:")
+		if language != "" && !strings.ContainsAny(language, ".$") {
+			return "languages." + language, nil
+		}
+	}
+	return "", fmt.Errorf("unknown metric %q", metric)
+}
+
+// parseStatsRange reads ranges like 30d, 12w, 3m and 1y. "all" has no lower
+// bound.
+func parseStatsRange(r string, now time.Time) (time.Time, error) {
+	if r == "all" {
+		return time.Time{}, nil
+	}
+	if len(r) < 2 {
+		return time.Time{}, fmt.Errorf("invalid range %q", r)
+	}
+	n, err := strconv.Atoi(r[:len(r)-1])
+	if err != nil || n < 1 {
+		return time.Time{}, fmt.Errorf("invalid range %q", r)
+	}
+
+	switch r[len(r)-1] {
+	case 'd':
+		return now.AddDate(0, 0, -n), nil
+	case 'w':
+		return now.AddDate(0, 0, -7*n), nil
+	case 'm':
+		return now.AddDate(0, -n, 0), nil
+	case 'y':
+		return now.AddDate(-n, 0, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid range %q", r)
+}
+
+func GetStatsHistory(c *fiber.Ctx) error {
+	metric := strings.TrimSpace(c.Query("metric", ""))
+	field, err := statsMetricField(metric)
+	if err != nil {
+		return util.ResponseAPI(c, fiber.StatusBadRequest, err.Error(), nil, "")
+	}
+
+	statsRange := c.Query("range", defaultStatsRange)
+	since, err := parseStatsRange(statsRange, time.Now())
+	if err != nil {
+		return util.ResponseAPI(c, fiber.StatusBadRequest, err.Error(), nil, "")
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"created_at": bson.M{"$gte": since},
+			field:        bson.M{"$exists": true},
+		}}},
+		{{Key: "$sort", Value: bson.M{"created_at": 1}}},
+		{{Key: "$project", Value: bson.M{"_id": 0, "created_at": 1, "value": "$" + field}}},
+	}
+
+	ctx := c.Context()
+	cursor, err := mgm.Coll(&models.StatsSnapshot{}).Aggregate(ctx, pipeline)
+	if err != nil {
+		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to load stats history", nil, "")
+	}
+	points := []models.StatsPoint{}
+	if err := cursor.All(ctx, &points); err != nil {
+		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to decode stats history", nil, "")
+	}
+
+	history := models.StatsHistory{
+		Points: points,
+		Metric: metric,
+		Range:  statsRange,
+	}
+	if len(points) > 0 {
+		history.First = points[0].Value
+		history.Last = points[len(points)-1].Value
+		history.Delta = history.Last - history.First
+	}
+	return util.ResponseAPI(c, fiber.StatusOK, "Stats history", history, "")
+}
@@ -0,0 +1,240 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/MishraShardendu22/models"
+	"github.com/MishraShardendu22/util"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kamva/mgm/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	githubUsernamePattern   = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]{0,37}[A-Za-z0-9])?$`)
+	leetcodeUsernamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,40}$`)
+	codeforcesHandlePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{3,24}$`)
+	atcoderHandlePattern    = regexp.MustCompile(`^[A-Za-z0-9_]{3,16}$`)
+)
+
+// The accounts the stats endpoints report on. Defaults come from the
+// environment; the stats_profile document overrides them per field.
+var (
+	statsProfileDefaults models.StatsProfile
+	statsProfile         models.StatsProfile
+	statsProfileMutex    sync.RWMutex
+)
+
+// SetStatsProfileDefaults installs the environment values used for any field
+// the stored profile leaves empty. Call it once at startup.
+func SetStatsProfileDefaults(defaults models.StatsProfile) {
+	if defaults.CalendarUsername == "" {
+		defaults.CalendarUsername = defaults.GitHubUsername
+	}
+	statsProfileMutex.Lock()
+	statsProfileDefaults = defaults
+	statsProfile = defaults
+	statsProfileMutex.Unlock()
+}
+
+func mergeStatsProfile(stored *models.StatsProfile) models.StatsProfile {
+	statsProfileMutex.RLock()
+	merged := statsProfileDefaults
+	statsProfileMutex.RUnlock()
+
+	merged.DefaultModel = stored.DefaultModel
+	if stored.GitHubUsername != "" {
+		// The calendar follows the GitHub account unless set on its own.
+		if merged.CalendarUsername == merged.GitHubUsername {
+			merged.CalendarUsername = stored.GitHubUsername
+		}
+		merged.GitHubUsername = stored.GitHubUsername
+	}
+	if stored.LeetCodeUsername != "" {
+		merged.LeetCodeUsername = stored.LeetCodeUsername
+	}
+	if stored.CalendarUsername != "" {
+		merged.CalendarUsername = stored.CalendarUsername
+	}
+	if stored.CommitsSince != "" {
+		merged.CommitsSince = stored.CommitsSince
+	}
+	// Optional fields emptied on purpose stay empty.
+	if stored.CodeforcesHandle != "" || slices.Contains(stored.Cleared, "codeforces_handle") {
+		merged.CodeforcesHandle = stored.CodeforcesHandle
+	}
+	if stored.AtCoderHandle != "" || slices.Contains(stored.Cleared, "atcoder_handle") {
+		merged.AtCoderHandle = stored.AtCoderHandle
+	}
+	if len(stored.Forges) > 0 || slices.Contains(stored.Cleared, "forges") {
+		merged.Forges = stored.Forges
+	}
+	return merged
+}
+
+// applyStatsProfileInput updates stored from the fields of a PUT body. A field left out
+// keeps its stored value and null hands it back to the environment. An
+// empty value does the same for the required fields, and clears the
+// optional handles and forges so the environment no longer fills them.
+func applyStatsProfileInput(stored *models.StatsProfile, input map[string]json.RawMessage) error {
+	fields := []struct {
+		name     string
+		value    *string
+		optional bool
+	}{
+		{"github_username", &stored.GitHubUsername, false},
+		{"leetcode_username", &stored.LeetCodeUsername, false},
+		{"calendar_username", &stored.CalendarUsername, false},
+		{"commits_since", &stored.CommitsSince, false},
+		{"codeforces_handle", &stored.CodeforcesHandle, true},
+		{"atcoder_handle", &stored.AtCoderHandle, true},
+	}
+	for _, f := range fields {
+		raw, ok := input[f.name]
+		if !ok {
+			continue
+		}
+		var value *string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return fmt.Errorf("%s must be a string or null", f.name)
+		}
+		*f.value = ""
+		if value != nil {
+			*f.value = *value
+		}
+		if f.optional {
+			setStatsFieldCleared(stored, f.name, value != nil && *value == "")
+		}
+	}
+
+	if raw, ok := input["forges"]; ok {
+		var forges []models.ForgeAccount
+		if err := json.Unmarshal(raw, &forges); err != nil {
+			return errors.New("forges must be a list or null")
+		}
+		// An empty list is not null: it drops the accounts from FORGE_ACCOUNTS.
+		setStatsFieldCleared(stored, "forges", forges != nil && len(forges) == 0)
+		if len(forges) == 0 {
+			forges = nil
+		}
+		stored.Forges = forges
+	}
+	return nil
+}
+
+func setStatsFieldCleared(stored *models.StatsProfile, name string, cleared bool) {
+	stored.Cleared = slices.DeleteFunc(stored.Cleared, func(n string) bool { return n == name })
+	if cleared {
+		stored.Cleared = append(stored.Cleared, name)
+	}
+}
+
+func validateStatsProfile(p *models.StatsProfile) error {
+	if !githubUsernamePattern.MatchString(p.GitHubUsername) {
+		return errors.New("invalid github_username")
+	}
+	if !leetcodeUsernamePattern.MatchString(p.LeetCodeUsername) {
+		return errors.New("invalid leetcode_username")
+	}
+	if !githubUsernamePattern.MatchString(p.CalendarUsername) {
+		return errors.New("invalid calendar_username")
+	}
+	// Competitive programming handles are optional.
+	if p.CodeforcesHandle != "" && !codeforcesHandlePattern.MatchString(p.CodeforcesHandle) {
+		return errors.New("invalid codeforces_handle")
+	}
+	if p.AtCoderHandle != "" && !atcoderHandlePattern.MatchString(p.AtCoderHandle) {
+		return errors.New("invalid atcoder_handle")
+	}
+	since, err := time.Parse(time.DateOnly, p.CommitsSince)
+	if err != nil {
+		return errors.New("commits_since must be a date like 2024-07-01")
+	}
+	if since.After(time.Now()) {
+		return errors.New("commits_since must not be in the future")
+	}
+	return validateForgeAccounts(p.Forges)
+}
+
+// LoadStatsProfile refreshes the stats accounts from Mongo and drops cached
+// stats when they changed. On failure the previous accounts stay in effect.
+func LoadStatsProfile() error {
+	stored := &models.StatsProfile{}
+	err := mgm.Coll(stored).First(bson.M{}, stored)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+
+	merged := mergeStatsProfile(stored)
+	setStatsProfile(merged)
+	return nil
+}
+
+func setStatsProfile(p models.StatsProfile) {
+	statsProfileMutex.Lock()
+	changed := statsProfile.GitHubUsername != p.GitHubUsername ||
+		statsProfile.LeetCodeUsername != p.LeetCodeUsername ||
+		statsProfile.CalendarUsername != p.CalendarUsername ||
+		statsProfile.CommitsSince != p.CommitsSince ||
+		statsProfile.CodeforcesHandle != p.CodeforcesHandle ||
+		statsProfile.AtCoderHandle != p.AtCoderHandle ||
+		!slices.Equal(statsProfile.Forges, p.Forges)
+	statsProfile = p
+	statsProfileMutex.Unlock()
+
+	if changed {
+		InvalidateStatsCache()
+	}
+}
+
+func currentStatsProfile() models.StatsProfile {
+	statsProfileMutex.RLock()
+	defer statsProfileMutex.RUnlock()
+	return statsProfile
+}
+
+func GetStatsProfile(c *fiber.Ctx) error {
+	return util.ResponseAPI(c, fiber.StatusOK, "Stats profile", currentStatsProfile(), "")
+}
+
+func UpdateStatsProfile(c *fiber.Ctx) error {
+	// Decoded field by field, so that null and a missing field differ.
+	var input map[string]json.RawMessage
+	if err := c.BodyParser(&input); err != nil {
+		return util.ResponseAPI(c, fiber.StatusBadRequest, "Invalid request body", nil, "")
+	}
+
+	stored := &models.StatsProfile{}
+	err := mgm.Coll(stored).First(bson.M{}, stored)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to load stats profile", nil, "")
+	}
+
+	if err := applyStatsProfileInput(stored, input); err != nil {
+		return util.ResponseAPI(c, fiber.StatusBadRequest, err.Error(), nil, "")
+	}
+
+	profile := mergeStatsProfile(stored)
+	if err := validateStatsProfile(&profile); err != nil {
+		return util.ResponseAPI(c, fiber.StatusBadRequest, err.Error(), nil, "")
+	}
+
+	if stored.ID.IsZero() {
+		err = mgm.Coll(stored).Create(stored)
+	} else {
+		err = mgm.Coll(stored).Update(stored)
+	}
+	if err != nil {
+		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to save stats profile", nil, "")
+	}
+
+	setStatsProfile(profile)
+	return util.ResponseAPI(c, fiber.StatusOK, "Stats profile updated successfully", profile, "")
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/MishraShardendu22/models"
+)
+
+func setStatsDefaults(t *testing.T, defaults models.StatsProfile) {
+	t.Helper()
+	old := statsProfileDefaults
+	SetStatsProfileDefaults(defaults)
+	t.Cleanup(func() { SetStatsProfileDefaults(old) })
+}
+
+func applyStatsBody(t *testing.T, stored *models.StatsProfile, body string) models.StatsProfile {
+	t.Helper()
+	var input map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(body), &input); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyStatsProfileInput(stored, input); err != nil {
+		t.Fatal(err)
+	}
+	return mergeStatsProfile(stored)
+}
+
+func TestStatsProfileInputClearsOptionalFields(t *testing.T) {
+	envForge := models.ForgeAccount{Kind: forgeGitLab, Username: "env"}
+	setStatsDefaults(t, models.StatsProfile{
+		GitHubUsername:   "env-gh",
+		CodeforcesHandle: "env_cf",
+		AtCoderHandle:    "env_ac",
+		Forges:           []models.ForgeAccount{envForge},
+	})
+	stored := &models.StatsProfile{}
+
+	p := applyStatsBody(t, stored, `{"codeforces_handle": "tourist", "forges": [{"kind": "gitea", "username": "me"}]}`)
+	if p.CodeforcesHandle != "tourist" || len(p.Forges) != 1 || p.Forges[0].Username != "me" {
+		t.Fatalf("unexpected profile %+v", p)
+	}
+
+	// Empty values clear; fields left out keep what was stored.
+	p = applyStatsBody(t, stored, `{"codeforces_handle": "", "forges": []}`)
+	if p.CodeforcesHandle != "" || len(p.Forges) != 0 {
+		t.Errorf("cleared fields came back as %q and %v", p.CodeforcesHandle, p.Forges)
+	}
+	if p.AtCoderHandle != "env_ac" || p.GitHubUsername != "env-gh" {
+		t.Errorf("untouched fields changed: %+v", p)
+	}
+
+	// null hands the field back to the environment.
+	p = applyStatsBody(t, stored, `{"codeforces_handle": null, "forges": null}`)
+	if p.CodeforcesHandle != "env_cf" || len(p.Forges) != 1 || p.Forges[0] != envForge {
+		t.Errorf("got %q and %v, want the environment", p.CodeforcesHandle, p.Forges)
+	}
+	if len(stored.Cleared) != 0 {
+		t.Errorf("cleared = %v, want none", stored.Cleared)
+	}
+}
+
+func TestStatsProfileInputResetsRequiredFields(t *testing.T) {
+	setStatsDefaults(t, models.StatsProfile{GitHubUsername: "env-gh", LeetCodeUsername: "env_lc"})
+	stored := &models.StatsProfile{}
+
+	p := applyStatsBody(t, stored, `{"github_username": "someone", "leetcode_username": "other"}`)
+	if p.GitHubUsername != "someone" || p.CalendarUsername != "someone" {
+		t.Fatalf("unexpected profile %+v", p)
+	}
+
+	p = applyStatsBody(t, stored, `{"github_username": "", "leetcode_username": null}`)
+	if p.GitHubUsername != "env-gh" || p.LeetCodeUsername != "env_lc" {
+		t.Errorf("got %+v, want the environment", p)
+	}
+}
+
+func TestStatsProfileInputRejectsWrongTypes(t *testing.T) {
+	for _, body := range []string{`{"atcoder_handle": 5}`, `{"forges": "gitlab:me"}`} {
+		var input map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(body), &input); err != nil {
+			t.Fatal(err)
+		}
+		if err := applyStatsProfileInput(&models.StatsProfile{}, input); err == nil {
+			t.Errorf("%s: no error", body)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/MishraShardendu22/models"
+	"github.com/kamva/mgm/v3"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var statsProfileCollection = mgm.CollName(&models.StatsProfile{})
+
+// WatchStatsProfile reloads the stats accounts whenever any replica changes
+// the stats_profile document. It follows a change stream on that collection
+// and falls back to polling every pollInterval when Mongo is a standalone
+// server without change streams.
+func WatchStatsProfile(ctx context.Context, logger *slog.Logger, pollInterval time.Duration) {
+	watchChanges(ctx, logger, "stats profile",
+		func(ctx context.Context) (bool, error) { return followStatsProfileStream(ctx, logger) },
+		func(ctx context.Context) { pollStatsProfile(ctx, logger, pollInterval) },
+	)
+}
+
+func followStatsProfileStream(ctx context.Context, logger *slog.Logger) (bool, error) {
+	stream, err := mgm.Coll(&models.StatsProfile{}).Watch(ctx, mongo.Pipeline{})
+	if err != nil {
+		return false, err
+	}
+	defer stream.Close(context.Background())
+
+	// The profile may have changed while disconnected.
+	if err := LoadStatsProfile(); err != nil {
+		logger.Warn("Failed to reload stats profile", "error", err)
+	}
+	logger.Info("Watching stats profile for changes")
+
+	// Every event means the one document changed, so each reloads it whole.
+	for stream.Next(ctx) {
+		if err := LoadStatsProfile(); err != nil {
+			logger.Warn("Failed to reload stats profile", "error", err)
+		}
+	}
+	return true, stream.Err()
+}
+
+// pollStatsProfile reloads the profile whenever its collection's count or
+// latest updated_at moves. A failed reload is retried on the next tick.
+func pollStatsProfile(ctx context.Context, logger *slog.Logger, interval time.Duration) {
+	logger.Info("Polling for stats profile changes", "interval", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	names := []string{statsProfileCollection}
+	last, err := collectionFingerprint(ctx, names)
+	if err != nil {
+		logger.Warn("Failed to poll stats profile", "error", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current, err := collectionFingerprint(ctx, names)
+		if err != nil {
+			logger.Warn("Failed to poll stats profile", "error", err)
+			continue
+		}
+		if current == last {
+			continue
+		}
+		if err := LoadStatsProfile(); err != nil {
+			logger.Warn("Failed to reload stats profile", "error", err)
+			continue
+		}
+		last = current
+	}
+}
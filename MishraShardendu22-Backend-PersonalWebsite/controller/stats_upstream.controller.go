@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MishraShardendu22/outbound"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Error codes returned to clients when an upstream stats API fails.
+const (
+	upstreamUnauthorized    = "upstream_unauthorized"
+	upstreamRateLimited     = "upstream_rate_limited"
+	upstreamTimeout         = "upstream_timeout"
+	upstreamNotFound        = "upstream_not_found"
+	upstreamUnavailable     = "upstream_unavailable"
+	upstreamInvalidResponse = "upstream_invalid_response"
+)
+
+// upstreamError is a failed call to a code forge, LeetCode or another stats
+// source.
+// Code is what the client sees; Status is the upstream HTTP status, if any.
+type upstreamError struct {
+	Err        error
+	RetryAfter time.Duration
+	Upstream   string
+	Code       string
+	Status     int
+}
+
+func (e *upstreamError) Error() string {
+	if e.Status != 0 {
+		return fmt.Sprintf("%s: %s (HTTP %d)", e.Upstream, e.Code, e.Status)
+	}
+	return fmt.Sprintf("%s: %s: %v", e.Upstream, e.Code, e.Err)
+}
+
+func (e *upstreamError) Unwrap() error { return e.Err }
+
+// httpStatus is the status this API answers with for the error.
+func (e *upstreamError) httpStatus() int {
+	switch e.Code {
+	case upstreamRateLimited:
+		return fiber.StatusServiceUnavailable
+	case upstreamTimeout:
+		return fiber.StatusGatewayTimeout
+	case upstreamNotFound:
+		return fiber.StatusNotFound
+	}
+	return fiber.StatusBadGateway
+}
+
+func (e *upstreamError) message() string {
+	switch e.Code {
+	case upstreamUnauthorized:
+		return e.Upstream + " rejected the configured credentials"
+	case upstreamRateLimited:
+		return e.Upstream + " rate limit exceeded"
+	case upstreamTimeout:
+		return e.Upstream + " did not respond in time"
+	case upstreamNotFound:
+		return e.Upstream + " account not found"
+	case upstreamInvalidResponse:
+		return e.Upstream + " returned an unexpected response"
+	}
+	return e.Upstream + " is unavailable"
+}
+
+// doUpstream sends req and decodes a successful JSON body into out. Transport
+// failures, open circuit breakers and non-2xx answers come back as
+// *upstreamError.
+func doUpstream(client *http.Client, upstream string, req *http.Request, out any) (http.Header, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		e := &upstreamError{Upstream: upstream, Code: upstreamUnavailable, Err: err}
+		var netErr net.Error
+		var open *outbound.OpenError
+		switch {
+		case errors.As(err, &open):
+			e.RetryAfter = open.RetryAfter
+		case errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()):
+			e.Code = upstreamTimeout
+		}
+		return nil, e
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		return resp.Header, statusError(upstream, resp)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.Header, &upstreamError{Upstream: upstream, Code: upstreamUnavailable, Err: err}
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return resp.Header, &upstreamError{Upstream: upstream, Code: upstreamInvalidResponse, Err: err}
+	}
+	return resp.Header, nil
+}
+
+// statusError classifies a non-2xx response. GitHub signals an exhausted
+// rate limit with 403 and X-RateLimit-Remaining: 0, not only with 429.
+func statusError(upstream string, resp *http.Response) *upstreamError {
+	e := &upstreamError{Upstream: upstream, Status: resp.StatusCode}
+	rateLimited := resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0")
+
+	switch {
+	case rateLimited:
+		e.Code = upstreamRateLimited
+		e.RetryAfter = retryAfter(resp.Header)
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		e.Code = upstreamUnauthorized
+	case resp.StatusCode == http.StatusNotFound:
+		e.Code = upstreamNotFound
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusGatewayTimeout:
+		e.Code = upstreamTimeout
+	default:
+		e.Code = upstreamUnavailable
+	}
+	return e
+}
+
+// retryAfter reads Retry-After in seconds, or the GitHub rate limit reset
+// time.
+func retryAfter(h http.Header) time.Duration {
+	if secs, err := strconv.Atoi(h.Get("Retry-After")); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		if wait := time.Until(time.Unix(reset, 0)); wait > 0 {
+			return wait.Round(time.Second)
+		}
+	}
+	return 0
+}
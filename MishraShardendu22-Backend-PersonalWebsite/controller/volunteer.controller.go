@@ -94,7 +94,7 @@ func AddVolunteerExperiences(c *fiber.Ctx) error {
 		return util.ResponseAPI(c, fiber.StatusBadRequest, "Organisation and at least one timeline entry are required", nil, "")
 	}
 
-	e.Tokens = util.GenerateTokens([]string{e.Organisation, e.Description}, e.Technologies)
+	e.Tokens = volunteerTokens(&e)
 
 	if err := mgm.Coll(&models.VolunteerExperience{}).Create(&e); err != nil {
 		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to add volunteer experience", nil, "")
@@ -110,7 +110,7 @@ func AddVolunteerExperiences(c *fiber.Ctx) error {
 		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to update user volunteer experiences", nil, "")
 	}
 
-	InvalidateSearchCache()
+	upsertSearchDocument(volunteerSearchDocument(&e))
 	return util.ResponseAPI(c, fiber.StatusOK, "Volunteer experience added successfully", e, "")
 }
 
@@ -148,13 +148,13 @@ func UpdateVolunteerExperiences(c *fiber.Ctx) error {
 	existing.OrganisationLogo = input.OrganisationLogo
 	existing.Images = input.Images
 
-	existing.Tokens = util.GenerateTokens([]string{existing.Organisation, existing.Description}, existing.Technologies)
+	existing.Tokens = volunteerTokens(&existing)
 
 	if err := mgm.Coll(&models.VolunteerExperience{}).Update(&existing); err != nil {
 		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to update volunteer experience", nil, "")
 	}
 
-	InvalidateSearchCache()
+	upsertSearchDocument(volunteerSearchDocument(&existing))
 	return util.ResponseAPI(c, fiber.StatusOK, "Volunteer experience updated successfully", existing, "")
 }
 
@@ -199,6 +199,6 @@ func RemoveVolunteerExperiences(c *fiber.Ctx) error {
 		return util.ResponseAPI(c, fiber.StatusInternalServerError, "Failed to delete volunteer experience", nil, "")
 	}
 
-	InvalidateSearchCache()
+	removeSearchDocument(expObjID.Hex())
 	return util.ResponseAPI(c, fiber.StatusOK, "Volunteer experience removed successfully", nil, "")
 }
@@ -9,8 +9,10 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/MishraShardendu22/controller"
 	"github.com/MishraShardendu22/database"
 	"github.com/MishraShardendu22/models"
+	"github.com/MishraShardendu22/outbound"
 	"github.com/MishraShardendu22/route"
 	"github.com/MishraShardendu22/util"
 	"github.com/gofiber/fiber/v2"
@@ -31,6 +33,30 @@ func loadConfig() *models.Config {
 		DbName:           util.GetEnv("DB_NAME", "test"),
 		AdminPass:        util.GetEnv("ADMIN_PASS", ""),
 		JWT_SECRET:       util.GetEnv("JWT_SECRET", ""),
+
+		SearchMinTokenLength: util.GetEnvInt("SEARCH_MIN_TOKEN_LENGTH", util.DefaultAnalyzerOptions.MinLength),
+		SearchStopWords:      util.GetEnvBool("SEARCH_STOP_WORDS", util.DefaultAnalyzerOptions.StopWords),
+		SearchStemming:       util.GetEnvBool("SEARCH_STEMMING", util.DefaultAnalyzerOptions.Stem),
+		SearchPollSeconds:    util.GetEnvInt("SEARCH_POLL_SECONDS", 5),
+		SearchEmbedder:       util.GetEnv("SEARCH_EMBEDDER", "lsa"),
+		SearchEmbeddingDims:  util.GetEnvInt("SEARCH_EMBEDDING_DIMS", 64),
+
+		GitHubUsername:     util.GetEnv("GITHUB_USERNAME", "MishraShardendu22"),
+		LeetCodeUsername:   util.GetEnv("LEETCODE_USERNAME", "ShardenduMishra22"),
+		CalendarUsername:   util.GetEnv("CALENDAR_USERNAME", ""),
+		GitHubCommitsSince: util.GetEnv("GITHUB_COMMITS_SINCE", "2024-07-01"),
+		GitHubMaxPages:     util.GetEnvInt("GITHUB_MAX_PAGES", 10),
+		StatsSnapshotHours: util.GetEnvInt("STATS_SNAPSHOT_HOURS", 24),
+		ForgeAccounts:      util.GetEnv("FORGE_ACCOUNTS", ""),
+		CodeforcesHandle:   util.GetEnv("CODEFORCES_HANDLE", ""),
+		AtCoderHandle:      util.GetEnv("ATCODER_HANDLE", ""),
+		RepoStatsHours:     util.GetEnvInt("REPO_STATS_HOURS", 6),
+
+		OutboundTimeoutSeconds: util.GetEnvInt("OUTBOUND_TIMEOUT_SECONDS", 10),
+		OutboundMaxRetries:     util.GetEnvInt("OUTBOUND_MAX_RETRIES", outbound.DefaultOptions.MaxRetries),
+		OutboundMaxPerHost:     util.GetEnvInt("OUTBOUND_MAX_PER_HOST", outbound.DefaultOptions.MaxPerHost),
+		BreakerThreshold:       util.GetEnvInt("BREAKER_THRESHOLD", outbound.DefaultOptions.BreakerThreshold),
+		BreakerCooldownSeconds: util.GetEnvInt("BREAKER_COOLDOWN_SECONDS", 30),
 	}
 	return config
 }
@@ -140,6 +166,68 @@ func main() {
 	setupLogger(config)
 	logger := slog.Default()
 
+	util.SetDefaultAnalyzer(util.NewAnalyzer(util.AnalyzerOptions{
+		MinLength: config.SearchMinTokenLength,
+		StopWords: config.SearchStopWords,
+		Stem:      config.SearchStemming,
+	}))
+	switch config.SearchEmbedder {
+	case "hash":
+		util.SetDefaultEmbedderFactory(util.HashingEmbedderFactory(max(config.SearchEmbeddingDims, 16)))
+	case "lsa":
+		util.SetDefaultEmbedderFactory(util.LSAEmbedderFactory(max(config.SearchEmbeddingDims, 1)))
+	default:
+		logger.Warn("Unknown search embedder, using lsa", "embedder", config.SearchEmbedder)
+	}
+	if err := controller.LoadSearchSettings(); err != nil {
+		logger.Warn("Failed to load search settings, using defaults", "error", err)
+	}
+	if err := controller.LoadSynonyms(); err != nil {
+		logger.Warn("Failed to load search synonyms", "error", err)
+	}
+	controller.SetStatsPageCap(config.GitHubMaxPages)
+	outboundOpts := outbound.DefaultOptions
+	outboundOpts.Timeout = time.Duration(config.OutboundTimeoutSeconds) * time.Second
+	outboundOpts.MaxRetries = config.OutboundMaxRetries
+	outboundOpts.MaxPerHost = config.OutboundMaxPerHost
+	outboundOpts.BreakerThreshold = config.BreakerThreshold
+	outboundOpts.BreakerCooldown = time.Duration(config.BreakerCooldownSeconds) * time.Second
+	controller.SetOutboundOptions(outboundOpts)
+	forges, err := controller.ParseForgeAccounts(config.ForgeAccounts)
+	if err != nil {
+		logger.Warn("Ignoring invalid FORGE_ACCOUNTS", "error", err)
+	}
+	controller.SetStatsProfileDefaults(models.StatsProfile{
+		GitHubUsername:   config.GitHubUsername,
+		LeetCodeUsername: config.LeetCodeUsername,
+		CalendarUsername: config.CalendarUsername,
+		CommitsSince:     config.GitHubCommitsSince,
+		CodeforcesHandle: config.CodeforcesHandle,
+		AtCoderHandle:    config.AtCoderHandle,
+		Forges:           forges,
+	})
+	if err := controller.LoadStatsProfile(); err != nil {
+		logger.Warn("Failed to load stats profile, using environment", "error", err)
+	}
+	analyticsCtx, stopAnalytics := context.WithCancel(context.Background())
+	analyticsDone := make(chan struct{})
+	go func() {
+		controller.StartSearchAnalytics(analyticsCtx, logger)
+		close(analyticsDone)
+	}()
+	go controller.StartStatsCollector(context.Background(), logger,
+		time.Duration(max(config.StatsSnapshotHours, 1))*time.Hour)
+	go controller.StartRepoStatsEnricher(context.Background(),
+		time.Duration(max(config.RepoStatsHours, 1))*time.Hour)
+	pollInterval := time.Duration(max(config.SearchPollSeconds, 1)) * time.Second
+	go controller.WatchSearchCollections(context.Background(), logger, pollInterval)
+	go controller.WatchStatsProfile(context.Background(), logger, pollInterval)
+	go func() {
+		if err := controller.SyncSearchTokens(context.Background(), logger); err != nil {
+			logger.Error("Failed to sync stored search tokens", "error", err)
+		}
+	}()
+
 	logger.Info("Starting Portfolio Backend",
 		"environment", config.Environment,
 		"port", config.Port,
@@ -184,11 +272,17 @@ func main() {
 	}()
 
 	gracefulShutdown(app, logger)
+
+	// No request is left to queue analytics; store what they queued.
+	stopAnalytics()
+	<-analyticsDone
 }
 
 func SetUpRoutes(app *fiber.App, logger *slog.Logger, config *models.Config) {
 	crudGroup := app.Group("/api", util.SetupCRUDAPILimiter(logger))
 	route.SetupSearchRoutes(crudGroup)
+	route.SetupRelatedRoutes(crudGroup)
+	route.SetupHealthRoutes(crudGroup)
 
 	statsGroup := app.Group("/api", util.SetupExternalAPILimiter(logger))
 	route.SetupStatsRoutes(statsGroup)
@@ -11,6 +11,81 @@ type Config struct {
 	DbName           string
 	AdminPass        string
 	JWT_SECRET       string
+
+	SearchMinTokenLength int
+	SearchStopWords      bool
+	SearchStemming       bool
+	SearchPollSeconds    int
+	SearchEmbedder       string
+	SearchEmbeddingDims  int
+
+	GitHubUsername     string
+	LeetCodeUsername   string
+	CalendarUsername   string
+	GitHubCommitsSince string
+	GitHubMaxPages     int
+	StatsSnapshotHours int
+	ForgeAccounts      string
+	CodeforcesHandle   string
+	AtCoderHandle      string
+	RepoStatsHours     int
+
+	OutboundTimeoutSeconds int
+	OutboundMaxRetries     int
+	OutboundMaxPerHost     int
+	BreakerThreshold       int
+	BreakerCooldownSeconds int
+}
+
+// SearchMeta records how the stored search tokens were produced.
+type SearchMeta struct {
+	mgm.DefaultModel  `bson:",inline"`
+	AnalyzerSignature string `bson:"analyzer_signature"`
+}
+
+func (*SearchMeta) CollectionName() string {
+	return "search_meta"
+}
+
+// SearchSettings holds the BM25F ranking parameters. Fields are scored
+// separately with their own weight and length normalisation b, then summed
+// before the k1 saturation. The recency boost decays with a half-life on
+// UpdatedAt and is off when RecencyWeight is 0.
+type SearchSettings struct {
+	mgm.DefaultModel    `bson:",inline" json:"-"`
+	FieldWeights        map[string]float64 `bson:"field_weights" json:"field_weights"`
+	FieldB              map[string]float64 `bson:"field_b" json:"field_b"`
+	TypeBoosts          map[string]float64 `bson:"type_boosts" json:"type_boosts"`
+	K1                  float64            `bson:"k1" json:"k1"`
+	RecencyWeight       float64            `bson:"recency_weight" json:"recency_weight"`
+	RecencyHalfLifeDays float64            `bson:"recency_half_life_days" json:"recency_half_life_days"`
+}
+
+func (*SearchSettings) CollectionName() string {
+	return "search_settings"
+}
+
+// StatsProfile names the accounts behind the GitHub, LeetCode and
+// contribution calendar endpoints. Empty fields fall back to the environment
+// unless named in Cleared. CommitsSince is a date (YYYY-MM-DD) bounding the
+// commit activity.
+type StatsProfile struct {
+	mgm.DefaultModel `bson:",inline" json:"-"`
+	GitHubUsername   string `bson:"github_username" json:"github_username"`
+	LeetCodeUsername string `bson:"leetcode_username" json:"leetcode_username"`
+	CalendarUsername string `bson:"calendar_username" json:"calendar_username"`
+	CommitsSince     string `bson:"commits_since" json:"commits_since"`
+	CodeforcesHandle string `bson:"codeforces_handle" json:"codeforces_handle"`
+	AtCoderHandle    string `bson:"atcoder_handle" json:"atcoder_handle"`
+	// Accounts on other forges, or other GitHub accounts, added to the stats
+	// of the GitHub account above.
+	Forges []ForgeAccount `bson:"forges" json:"forges"`
+	// Optional fields, by JSON name, the admin emptied on purpose.
+	Cleared []string `bson:"cleared,omitempty" json:"-"`
+}
+
+func (*StatsProfile) CollectionName() string {
+	return "stats_profile"
 }
 
 type TestModel struct {
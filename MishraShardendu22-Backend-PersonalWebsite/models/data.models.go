@@ -2,6 +2,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/kamva/mgm/v3"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -30,6 +32,8 @@ type Project struct {
 	SmallDescription  string   `bson:"small_description" json:"small_description"`
 	ProjectRepository string   `bson:"project_repository" json:"project_repository"`
 	Order             int      `bson:"order" json:"order"`
+	// Filled in by the repository stats enricher, never by clients.
+	RepoStats *RepoStats `bson:"repo_stats,omitempty" json:"repo_stats,omitempty"`
 }
 
 type Experience struct {
@@ -85,6 +89,13 @@ type VolunteerExperienceTimeLine struct {
 	StartDate           string `bson:"start_date" json:"start_date"`
 }
 
+// Synonym is a group of interchangeable search terms, e.g. "k8s" and
+// "kubernetes". Terms may span several words.
+type Synonym struct {
+	mgm.DefaultModel `bson:",inline" json:"inline"`
+	Terms            []string `bson:"terms" json:"terms"`
+}
+
 type UpdatedProject struct {
 	ProjectID primitive.ObjectID `bson:"project_id" json:"project_id"`
 	Order     int                `bson:"order" json:"order"`
@@ -97,29 +108,197 @@ type ProjectKanban struct {
 }
 
 type SearchResult struct {
-	Skills      []string `json:"skills,omitempty"`
-	ID          string   `json:"id"`
-	Type        string   `json:"type"`
-	Title       string   `json:"title"`
-	Subtitle    string   `json:"subtitle,omitempty"`
-	Description string   `json:"description"`
-	URL         string   `json:"url"`
-	Score       float64  `json:"score"`
+	Skills      []string          `json:"skills,omitempty"`
+	Highlights  *SearchHighlights `json:"highlights,omitempty"`
+	ID          string            `json:"id"`
+	Type        string            `json:"type"`
+	Title       string            `json:"title"`
+	Subtitle    string            `json:"subtitle,omitempty"`
+	Description string            `json:"description"`
+	URL         string            `json:"url"`
+	Score       float64           `json:"score"`
+}
+
+// HighlightSpan marks a matched token by UTF-16 code unit offsets, the way
+// JavaScript indexes strings, end exclusive.
+type HighlightSpan struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+type SearchHighlights struct {
+	Title       []HighlightSpan `json:"title,omitempty"`
+	Subtitle    []HighlightSpan `json:"subtitle,omitempty"`
+	Description []HighlightSpan `json:"description,omitempty"`
 }
 
 type SearchResponse struct {
 	Results    []SearchResult `json:"results"`
+	Facets     *SearchFacets  `json:"facets,omitempty"`
 	Query      string         `json:"query"`
+	DidYouMean string         `json:"did_you_mean,omitempty"`
+	QueryID    string         `json:"query_id,omitempty"`
+	Mode       string         `json:"mode"`
 	TotalCount int            `json:"total_count"`
 }
 
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type SearchFacets struct {
+	Types  []FacetCount `json:"types"`
+	Skills []FacetCount `json:"skills"`
+}
+
+type SearchSuggestion struct {
+	Text  string  `json:"text"`
+	Type  string  `json:"type"`
+	Score float64 `json:"score"`
+}
+
 type SearchDocument struct {
-	Skills      []string `json:"-"`
-	Tokens      []string `json:"-"`
-	ID          string   `json:"-"`
-	Type        string   `json:"-"`
-	Title       string   `json:"-"`
-	Subtitle    string   `json:"-"`
-	Description string   `json:"-"`
-	URL         string   `json:"-"`
+	Skills      []string  `json:"-"`
+	Tokens      []string  `json:"-"`
+	Years       []int     `json:"-"`
+	Positions   []string  `json:"-"`
+	Hosts       []string  `json:"-"`
+	UpdatedAt   time.Time `json:"-"`
+	ID          string    `json:"-"`
+	Type        string    `json:"-"`
+	Title       string    `json:"-"`
+	Subtitle    string    `json:"-"`
+	Summary     string    `json:"-"`
+	Issuer      string    `json:"-"`
+	Description string    `json:"-"`
+	URL         string    `json:"-"`
+}
+
+type RelatedItem struct {
+	Skills       []string `json:"skills,omitempty"`
+	SharedSkills []string `json:"shared_skills,omitempty"`
+	ID           string   `json:"id"`
+	Type         string   `json:"type"`
+	Title        string   `json:"title"`
+	Subtitle     string   `json:"subtitle,omitempty"`
+	Description  string   `json:"description"`
+	URL          string   `json:"url"`
+	Score        float64  `json:"score"`
+}
+
+type SearchFilters struct {
+	Skills []string `bson:"skills,omitempty" json:"skills,omitempty"`
+	Type   string   `bson:"type,omitempty" json:"type,omitempty"`
+	Year   string   `bson:"year,omitempty" json:"year,omitempty"`
+	Mode   string   `bson:"mode,omitempty" json:"mode,omitempty"`
+	Fuzzy  bool     `bson:"fuzzy" json:"fuzzy"`
+}
+
+// SearchQueryLog records one search. It holds no visitor identifiers; the
+// query is stored only as its normalized tokens.
+type SearchQueryLog struct {
+	mgm.DefaultModel `bson:",inline" json:"inline"`
+	Tokens           []string      `bson:"tokens" json:"tokens"`
+	Filters          SearchFilters `bson:"filters" json:"filters"`
+	Normalized       string        `bson:"normalized" json:"normalized"`
+	ResultCount      int           `bson:"result_count" json:"result_count"`
+}
+
+// SearchClick records a visitor opening a result of a logged search.
+type SearchClick struct {
+	mgm.DefaultModel `bson:",inline" json:"inline"`
+	ResultID         string             `bson:"result_id" json:"result_id"`
+	ResultType       string             `bson:"result_type" json:"result_type"`
+	QueryID          primitive.ObjectID `bson:"query_id" json:"query_id"`
+	Position         int                `bson:"position" json:"position"`
+}
+
+type SearchQueryStat struct {
+	LastSearched time.Time `bson:"last_searched" json:"last_searched"`
+	Query        string    `bson:"_id" json:"query"`
+	AvgResults   float64   `bson:"avg_results" json:"avg_results"`
+	Count        int       `bson:"count" json:"count"`
+}
+
+type SearchClickStat struct {
+	ResultID    string  `bson:"_id" json:"result_id"`
+	ResultType  string  `bson:"result_type" json:"result_type"`
+	AvgPosition float64 `bson:"avg_position" json:"avg_position"`
+	Clicks      int     `bson:"clicks" json:"clicks"`
+	Queries     int     `bson:"queries" json:"queries"`
+}
+
+type ReindexCount struct {
+	Total     int `json:"total"`
+	Processed int `json:"processed"`
+	Updated   int `json:"updated"`
+}
+
+// ReindexStatus reports the progress of the last admin reindex.
+type ReindexStatus struct {
+	Counts     map[string]ReindexCount `json:"counts"`
+	StartedAt  time.Time               `json:"started_at,omitzero"`
+	FinishedAt time.Time               `json:"finished_at,omitzero"`
+	Collection string                  `json:"collection,omitempty"`
+	Error      string                  `json:"error,omitempty"`
+	Documents  int                     `json:"documents"`
+	Running    bool                    `json:"running"`
+}
+
+// RepoStats is live data about a project's repository. Repository is the
+// link it was fetched for, so stats of a link since replaced are not shown.
+type RepoStats struct {
+	PushedAt   time.Time `bson:"pushed_at" json:"pushed_at"`
+	FetchedAt  time.Time `bson:"fetched_at" json:"fetched_at"`
+	Topics     []string  `bson:"topics" json:"topics"`
+	Repository string    `bson:"repository" json:"repository"`
+	Forge      string    `bson:"forge" json:"forge"`
+	FullName   string    `bson:"full_name" json:"full_name"`
+	Language   string    `bson:"language" json:"language"`
+	Stars      int       `bson:"stars" json:"stars"`
+	Forks      int       `bson:"forks" json:"forks"`
+	OpenIssues int       `bson:"open_issues" json:"open_issues"`
+}
+
+// RepoStatsRefresh reports the progress of the last repository stats run.
+type RepoStatsRefresh struct {
+	StartedAt   time.Time `json:"started_at,omitzero"`
+	FinishedAt  time.Time `json:"finished_at,omitzero"`
+	Error       string    `json:"error,omitempty"`
+	Updated     int       `json:"updated"`
+	Fresh       int       `json:"fresh"`
+	Unsupported int       `json:"unsupported"`
+	Failed      int       `json:"failed"`
+	Running     bool      `json:"running"`
+}
+
+// StatsSnapshot records the GitHub and LeetCode numbers at one point in
+// time. Metrics that could not be fetched for a snapshot are left out.
+// Bucket is the start of the collection interval the snapshot belongs to;
+// there is at most one snapshot per bucket.
+type StatsSnapshot struct {
+	mgm.DefaultModel `bson:",inline" json:"inline"`
+	Bucket           time.Time      `bson:"bucket" json:"bucket"`
+	Languages        map[string]int `bson:"languages,omitempty" json:"languages,omitempty"`
+	LeetCodeSolved   map[string]int `bson:"leetcode_solved,omitempty" json:"leetcode_solved,omitempty"`
+	Followers        *int           `bson:"followers,omitempty" json:"followers,omitempty"`
+	Stars            *int           `bson:"stars,omitempty" json:"stars,omitempty"`
+	Truncated        bool           `bson:"truncated" json:"truncated"`
+}
+
+type StatsPoint struct {
+	Time  time.Time `bson:"created_at" json:"time"`
+	Value float64   `bson:"value" json:"value"`
+}
+
+// StatsHistory is the time series of one metric with its change over the
+// requested range.
+type StatsHistory struct {
+	Points []StatsPoint `json:"points"`
+	Metric string       `json:"metric"`
+	Range  string       `json:"range"`
+	First  float64      `json:"first"`
+	Last   float64      `json:"last"`
+	Delta  float64      `json:"delta"`
 }
@@ -0,0 +1,110 @@
+package models
+
+import "time"
+
+type GitHubProfile struct {
+	CreatedAt   time.Time `json:"created_at"`
+	Login       string    `json:"login"`
+	Name        string    `json:"name"`
+	AvatarURL   string    `json:"avatar_url"`
+	HTMLURL     string    `json:"html_url"`
+	Bio         string    `json:"bio"`
+	Company     string    `json:"company"`
+	Blog        string    `json:"blog"`
+	Location    string    `json:"location"`
+	PublicRepos int       `json:"public_repos"`
+	Followers   int       `json:"followers"`
+	Following   int       `json:"following"`
+}
+
+type GitHubStars struct {
+	Stars     int  `json:"stars"`
+	Truncated bool `json:"truncated"`
+}
+
+type CommitDay struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+type RepoSummary struct {
+	Forge           string `json:"forge"`
+	Name            string `json:"name"`
+	HTMLURL         string `json:"html_url"`
+	Description     string `json:"description"`
+	Language        string `json:"language"`
+	StargazersCount int    `json:"stargazers_count"`
+}
+
+type ContributionDay struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+	Level int    `json:"level"`
+}
+
+// ContributionCalendar has one entry per day from the first contribution
+// year through today. Weeks lays out the last year the way GitHub draws it:
+// columns of seven days, Sunday first, the last one cut off at today.
+type ContributionCalendar struct {
+	Total         map[string]int      `json:"total"`
+	Contributions []ContributionDay   `json:"contributions"`
+	Weeks         [][]ContributionDay `json:"weeks"`
+	CurrentStreak int                 `json:"current_streak"`
+	LongestStreak int                 `json:"longest_streak"`
+	Source        string              `json:"source"`
+}
+
+type SolvedCount struct {
+	Difficulty string `json:"difficulty"`
+	Count      int    `json:"count"`
+}
+
+type LeetCodeStats struct {
+	Solved   []SolvedCount `json:"solved"`
+	Username string        `json:"username"`
+	RealName string        `json:"real_name"`
+	Avatar   string        `json:"avatar"`
+	Ranking  int           `json:"ranking"`
+}
+
+// ForgeAccount is one code-forge account the stats aggregate over. BaseURL
+// is the API root; TokenEnv names the environment variable holding its token.
+type ForgeAccount struct {
+	Kind     string `bson:"kind" json:"kind"`
+	BaseURL  string `bson:"base_url" json:"base_url"`
+	Username string `bson:"username" json:"username"`
+	TokenEnv string `bson:"token_env,omitempty" json:"token_env,omitempty"`
+}
+
+type ForgeProfile struct {
+	Forge     string `json:"forge"`
+	BaseURL   string `json:"base_url"`
+	Username  string `json:"username"`
+	Name      string `json:"name"`
+	AvatarURL string `json:"avatar_url"`
+	HTMLURL   string `json:"html_url"`
+	Followers int    `json:"followers"`
+	Following int    `json:"following"`
+}
+
+type CPContest struct {
+	Name   string    `json:"name"`
+	Date   time.Time `json:"date"`
+	Rank   int       `json:"rank"`
+	Rating int       `json:"rating"`
+}
+
+// CPProfile is a competitive programming account, normalized across
+// platforms. Rank is the platform's title for the rating, such as "expert"
+// on Codeforces or "Knight" on LeetCode. Error holds the upstream error code
+// when the platform could not be read; the other fields are then empty.
+type CPProfile struct {
+	Platform  string        `json:"platform"`
+	Handle    string        `json:"handle"`
+	Rating    int           `json:"rating"`
+	MaxRating int           `json:"max_rating"`
+	Rank      string        `json:"rank"`
+	Solved    []SolvedCount `json:"solved"`
+	Contests  []CPContest   `json:"contests"`
+	Error     string        `json:"error,omitempty"`
+}
@@ -0,0 +1,126 @@
+package outbound
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Breaker states. A closed breaker lets every request through; an open one
+// fails them fast; a half-open one lets a single probe through and closes
+// or opens again on its result.
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half-open"
+)
+
+// HostStatus is a host's breaker as reported by Transport.Hosts.
+type HostStatus struct {
+	OpenedAt time.Time `json:"opened_at,omitzero"`
+	Host     string    `json:"host"`
+	State    string    `json:"state"`
+	Failures int       `json:"failures"`
+	InFlight int       `json:"in_flight"`
+}
+
+// OpenError is returned instead of calling a host whose breaker is open.
+type OpenError struct {
+	Host       string
+	RetryAfter time.Duration
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("circuit breaker open for %s, retry in %s", e.Host, e.RetryAfter)
+}
+
+// breaker counts consecutive failures of one host. Only 5xx answers and
+// transport failures count; a 4xx means the host is up.
+type breaker struct {
+	mu        sync.Mutex
+	host      string
+	threshold int
+	cooldown  time.Duration
+	state     string
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	inFlight  int
+}
+
+// allow admits a request, or returns an *OpenError while the breaker is
+// open or its half-open probe is still running.
+func (b *breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if wait := b.cooldown - time.Since(b.openedAt); wait > 0 {
+			return &OpenError{Host: b.host, RetryAfter: wait.Round(time.Second)}
+		}
+		b.state = StateHalfOpen
+		slog.Info("Circuit breaker half-open, probing host", "host", b.host)
+		fallthrough
+	case StateHalfOpen:
+		if b.probing {
+			return &OpenError{Host: b.host, RetryAfter: time.Second}
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.probing = false
+	if b.state != StateClosed {
+		b.state = StateClosed
+		slog.Info("Circuit breaker closed", "host", b.host)
+	}
+}
+
+func (b *breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.probing = false
+	if b.state == StateOpen || (b.state == StateClosed && b.failures < b.threshold) {
+		return
+	}
+	b.state = StateOpen
+	b.openedAt = time.Now()
+	slog.Warn("Circuit breaker opened", "host", b.host,
+		"failures", b.failures, "cooldown", b.cooldown)
+}
+
+// abandon frees the probe slot of a request whose caller gave up.
+func (b *breaker) abandon() {
+	b.mu.Lock()
+	b.probing = false
+	b.mu.Unlock()
+}
+
+func (b *breaker) track(delta int) {
+	b.mu.Lock()
+	b.inFlight += delta
+	b.mu.Unlock()
+}
+
+func (b *breaker) status() HostStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	status := HostStatus{
+		Host:     b.host,
+		State:    b.state,
+		Failures: b.failures,
+		InFlight: b.inFlight,
+	}
+	if b.state != StateClosed {
+		status.OpenedAt = b.openedAt
+	}
+	return status
+}
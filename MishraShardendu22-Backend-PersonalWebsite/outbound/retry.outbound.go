@@ -0,0 +1,41 @@
+package outbound
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type idempotentKey struct{}
+
+// Idempotent marks requests made with the returned context as safe to
+// retry whatever their method, such as GraphQL queries sent with POST.
+func Idempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+func isIdempotent(ctx context.Context) bool {
+	marked, _ := ctx.Value(idempotentKey{}).(bool)
+	return marked
+}
+
+// backoff waits a random time up to base doubled per attempt, capped at
+// limit, so clients that failed together do not retry together.
+func backoff(base, limit time.Duration, attempt int) time.Duration {
+	ceiling := limit
+	if attempt < 30 {
+		ceiling = min(base<<attempt, limit)
+	}
+	return rand.N(ceiling) + 1
+}
+
+// retryAfter reads a Retry-After header given in seconds.
+func retryAfter(h http.Header) (time.Duration, bool) {
+	secs, err := strconv.Atoi(h.Get("Retry-After"))
+	if err != nil || secs < 0 {
+		return 0, false
+	}
+	return time.Duration(secs) * time.Second, true
+}
@@ -0,0 +1,250 @@
+// Package outbound makes calls to third-party APIs. Its transport retries
+// idempotent requests with jittered backoff, limits how many requests run
+// against one host at a time, and stops calling a host that keeps failing
+// until it has had time to recover.
+package outbound
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/semaphore"
+)
+
+type Options struct {
+	// Timeout bounds a single attempt, reading the body included.
+	Timeout time.Duration
+	// MaxRetries is how many times a failed idempotent request is retried.
+	MaxRetries  int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// MaxPerHost caps the requests running against one host; the rest wait.
+	MaxPerHost int
+	// After BreakerThreshold consecutive failures a host's breaker opens and
+	// calls fail fast for BreakerCooldown.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+var DefaultOptions = Options{
+	Timeout:          10 * time.Second,
+	MaxRetries:       2,
+	BaseBackoff:      250 * time.Millisecond,
+	MaxBackoff:       5 * time.Second,
+	MaxPerHost:       8,
+	BreakerThreshold: 5,
+	BreakerCooldown:  30 * time.Second,
+}
+
+// Transport is an http.RoundTripper that adds retries, per-host limits and
+// per-host circuit breakers to http.DefaultTransport.
+type Transport struct {
+	base  http.RoundTripper
+	opts  Options
+	mu    sync.Mutex
+	hosts map[string]*host
+}
+
+// host is the limiter and breaker shared by every request to one host.
+type host struct {
+	sem *semaphore.Weighted
+	breaker
+}
+
+func NewTransport(opts Options) *Transport {
+	opts.Timeout = max(opts.Timeout, time.Second)
+	opts.MaxRetries = max(opts.MaxRetries, 0)
+	opts.BaseBackoff = max(opts.BaseBackoff, time.Millisecond)
+	opts.MaxBackoff = max(opts.MaxBackoff, opts.BaseBackoff)
+	opts.MaxPerHost = max(opts.MaxPerHost, 1)
+	opts.BreakerThreshold = max(opts.BreakerThreshold, 1)
+	opts.BreakerCooldown = max(opts.BreakerCooldown, time.Second)
+
+	return &Transport{
+		base:  http.DefaultTransport,
+		opts:  opts,
+		hosts: make(map[string]*host),
+	}
+}
+
+func (t *Transport) host(name string) *host {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h, ok := t.hosts[name]
+	if !ok {
+		h = &host{
+			sem: semaphore.NewWeighted(int64(t.opts.MaxPerHost)),
+			breaker: breaker{
+				host:      name,
+				state:     StateClosed,
+				threshold: t.opts.BreakerThreshold,
+				cooldown:  t.opts.BreakerCooldown,
+			},
+		}
+		t.hosts[name] = h
+	}
+	return h
+}
+
+// Hosts reports the breaker of every host called so far, by host name.
+func (t *Transport) Hosts() []HostStatus {
+	t.mu.Lock()
+	hosts := make([]*host, 0, len(t.hosts))
+	for _, h := range t.hosts {
+		hosts = append(hosts, h)
+	}
+	t.mu.Unlock()
+
+	statuses := make([]HostStatus, len(hosts))
+	for i, h := range hosts {
+		statuses[i] = h.status()
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Host < statuses[j].Host
+	})
+	return statuses
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	h := t.host(req.URL.Host)
+	ctx := req.Context()
+	retryable := canRetry(req)
+
+	for attempt := 0; ; attempt++ {
+		try := req
+		if attempt > 0 && req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			try = req.Clone(ctx)
+			try.Body = body
+		}
+
+		resp, err := t.attempt(h, try)
+		if !retryable || attempt >= t.opts.MaxRetries || ctx.Err() != nil {
+			return resp, err
+		}
+		delay, ok := t.retryDelay(resp, err, attempt)
+		if !ok {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt sends req once, holding one of the host's slots until the
+// response body is closed.
+func (t *Transport) attempt(h *host, req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if err := h.sem.Acquire(ctx, 1); err != nil {
+		closeBody(req)
+		return nil, err
+	}
+	if err := h.allow(); err != nil {
+		h.sem.Release(1)
+		closeBody(req)
+		return nil, err
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, t.opts.Timeout)
+	h.track(1)
+	done := func() {
+		cancel()
+		h.track(-1)
+		h.sem.Release(1)
+	}
+
+	resp, err := t.base.RoundTrip(req.WithContext(attemptCtx))
+	switch {
+	case err != nil && ctx.Err() != nil:
+		// The caller gave up; that says nothing about the host.
+		h.abandon()
+	case err != nil || resp.StatusCode >= 500:
+		h.failure()
+	default:
+		h.success()
+	}
+
+	if err != nil {
+		done()
+		return nil, err
+	}
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: done}
+	return resp, nil
+}
+
+// releaseBody frees the attempt's slot and timeout once the caller is done
+// with the body.
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// closeBody closes the body of a request that is not sent, as the
+// RoundTripper contract requires.
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// canRetry reports whether req may be sent again: its method is idempotent
+// or its context was marked with Idempotent, and its body can be replayed.
+func canRetry(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return isIdempotent(req.Context())
+}
+
+// retryDelay decides whether a failed attempt is worth retrying and how
+// long to wait first. Open breakers are not retried, and neither is a
+// Retry-After longer than MaxBackoff: the caller is better off reporting it.
+func (t *Transport) retryDelay(resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if err != nil {
+		var open *OpenError
+		if errors.As(err, &open) {
+			return 0, false
+		}
+		return backoff(t.opts.BaseBackoff, t.opts.MaxBackoff, attempt), true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+	default:
+		return 0, false
+	}
+	if wait, ok := retryAfter(resp.Header); ok {
+		return wait, wait <= t.opts.MaxBackoff
+	}
+	return backoff(t.opts.BaseBackoff, t.opts.MaxBackoff, attempt), true
+}
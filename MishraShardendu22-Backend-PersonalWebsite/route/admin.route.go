@@ -12,4 +12,25 @@ func SetupAdminRoutes(router fiber.Router, adminPass string, jwtSecret string) {
 	})
 
 	router.Get("/admin/auth", middleware.JWTMiddleware(jwtSecret), controller.AdminGet)
+
+	router.Get("/admin/search/settings", middleware.JWTMiddleware(jwtSecret), controller.GetSearchSettings)
+	router.Put("/admin/search/settings", middleware.JWTMiddleware(jwtSecret), controller.UpdateSearchSettings)
+
+	router.Post("/admin/search/reindex", middleware.JWTMiddleware(jwtSecret), controller.StartReindex)
+	router.Get("/admin/search/reindex", middleware.JWTMiddleware(jwtSecret), controller.GetReindexStatus)
+
+	router.Get("/admin/search/synonyms", middleware.JWTMiddleware(jwtSecret), controller.GetSynonyms)
+	router.Post("/admin/search/synonyms", middleware.JWTMiddleware(jwtSecret), controller.AddSynonym)
+	router.Put("/admin/search/synonyms/:id", middleware.JWTMiddleware(jwtSecret), controller.UpdateSynonym)
+	router.Delete("/admin/search/synonyms/:id", middleware.JWTMiddleware(jwtSecret), controller.RemoveSynonym)
+
+	router.Get("/admin/search/analytics/top", middleware.JWTMiddleware(jwtSecret), controller.GetTopSearchQueries)
+	router.Get("/admin/search/analytics/zero-results", middleware.JWTMiddleware(jwtSecret), controller.GetZeroResultQueries)
+	router.Get("/admin/search/analytics/clicks", middleware.JWTMiddleware(jwtSecret), controller.GetSearchClickThrough)
+
+	router.Post("/admin/projects/repo-stats", middleware.JWTMiddleware(jwtSecret), controller.StartRepoStatsRefresh)
+	router.Get("/admin/projects/repo-stats", middleware.JWTMiddleware(jwtSecret), controller.GetRepoStatsRefresh)
+
+	router.Get("/admin/stats/profile", middleware.JWTMiddleware(jwtSecret), controller.GetStatsProfile)
+	router.Put("/admin/stats/profile", middleware.JWTMiddleware(jwtSecret), controller.UpdateStatsProfile)
 }
@@ -0,0 +1,10 @@
+package route
+
+import (
+	"github.com/MishraShardendu22/controller"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupRelatedRoutes(router fiber.Router) {
+	router.Get("/related/:type/:id", controller.GetRelatedItems)
+}
@@ -10,4 +10,5 @@ func SetupSearchRoutes(router fiber.Router) {
 
 	searchGroup.Get("/", controller.Search)
 	searchGroup.Get("/suggestions", controller.GetSearchSuggestions)
+	searchGroup.Post("/click", controller.RecordSearchClick)
 }
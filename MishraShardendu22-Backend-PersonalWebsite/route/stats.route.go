@@ -6,7 +6,8 @@ import (
 )
 
 func SetupStatsRoutes(router fiber.Router) {
-	// GitHub Stats Routes - All public, no authentication required
+	// GitHub Stats Routes - All public, no authentication required. Stars,
+	// commits, languages and top repos include the extra forge accounts
 	router.Get("/github", controller.FetchGitHubProfile)
 	router.Get("/github/stars", controller.FetchGitHubStars)
 	router.Get("/github/commits", controller.FetchGitHubCommits)
@@ -14,6 +15,15 @@ func SetupStatsRoutes(router fiber.Router) {
 	router.Get("/github/top-repos", controller.FetchTopStarredRepos)
 	router.Get("/github/calendar", controller.FetchContributionCalendar)
 
+	// Profiles of every configured forge account
+	router.Get("/forges", controller.FetchForgeProfiles)
+
 	// LeetCode Stats Routes - All public, no authentication required
 	router.Get("/leetcode", controller.FetchLeetCodeData)
+
+	// LeetCode, Codeforces and AtCoder in one shape
+	router.Get("/cp", controller.FetchCPProfiles)
+
+	// Stored snapshots of the above, for trends
+	router.Get("/stats/history", controller.GetStatsHistory)
 }
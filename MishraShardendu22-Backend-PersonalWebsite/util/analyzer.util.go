@@ -0,0 +1,206 @@
+package util
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Analyzer turns text into the terms stored in the search index. Documents
+// and queries must go through the same analyzer for their terms to meet.
+type Analyzer interface {
+	Analyze(text string) []string
+	AnalyzeSpans(text string) []TokenSpan
+	// Signature identifies the configuration, so stored tokens produced by a
+	// different pipeline can be detected and rebuilt.
+	Signature() string
+}
+
+// Tokenizer splits raw text into tokens, keeping their rune offsets.
+type Tokenizer interface {
+	Split(text string) []TokenSpan
+	Name() string
+}
+
+// TokenFilter rewrites a single token, or drops it by returning false.
+type TokenFilter interface {
+	Apply(token string) (string, bool)
+	Name() string
+}
+
+type Pipeline struct {
+	Tokenizer Tokenizer
+	Filters   []TokenFilter
+}
+
+func (p *Pipeline) AnalyzeSpans(text string) []TokenSpan {
+	spans := p.Tokenizer.Split(text)
+	kept := spans[:0]
+	for _, span := range spans {
+		token, ok := span.Token, true
+		for _, filter := range p.Filters {
+			if token, ok = filter.Apply(token); !ok {
+				break
+			}
+		}
+		if ok {
+			span.Token = token
+			kept = append(kept, span)
+		}
+	}
+	return kept
+}
+
+func (p *Pipeline) Analyze(text string) []string {
+	spans := p.AnalyzeSpans(text)
+	tokens := make([]string, len(spans))
+	for i, span := range spans {
+		tokens[i] = span.Token
+	}
+	return tokens
+}
+
+func (p *Pipeline) Signature() string {
+	names := make([]string, 0, len(p.Filters)+1)
+	names = append(names, p.Tokenizer.Name())
+	for _, filter := range p.Filters {
+		names = append(names, filter.Name())
+	}
+	return strings.Join(names, "+")
+}
+
+// WordTokenizer splits on anything that is not a letter or a number.
+type WordTokenizer struct{}
+
+func (WordTokenizer) Name() string { return "word" }
+
+func (WordTokenizer) Split(text string) []TokenSpan {
+	estimatedTokens := len(text) / 6
+	if estimatedTokens < 8 {
+		estimatedTokens = 8
+	}
+	spans := make([]TokenSpan, 0, estimatedTokens)
+	start, pos := -1, 0
+	var builder strings.Builder
+	builder.Grow(32)
+
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			if start < 0 {
+				start = pos
+			}
+			builder.WriteRune(r)
+		} else if start >= 0 {
+			spans = append(spans, TokenSpan{Token: builder.String(), Start: start, End: pos})
+			builder.Reset()
+			start = -1
+		}
+		pos++
+	}
+
+	if start >= 0 {
+		spans = append(spans, TokenSpan{Token: builder.String(), Start: start, End: pos})
+	}
+	return spans
+}
+
+type LowercaseFilter struct{}
+
+func (LowercaseFilter) Name() string { return "lower" }
+
+func (LowercaseFilter) Apply(token string) (string, bool) {
+	return strings.ToLower(token), true
+}
+
+// MinLengthFilter drops tokens shorter than Min runes, except those in Keep.
+type MinLengthFilter struct {
+	Min  int
+	Keep []string
+}
+
+func (f MinLengthFilter) Name() string {
+	name := "min" + strconv.Itoa(f.Min)
+	if len(f.Keep) > 0 {
+		name += "(" + strings.Join(f.Keep, ",") + ")"
+	}
+	return name
+}
+
+func (f MinLengthFilter) Apply(token string) (string, bool) {
+	return token, utf8.RuneCountInString(token) >= f.Min || slices.Contains(f.Keep, token)
+}
+
+type StopWordFilter struct {
+	words map[string]struct{}
+}
+
+func NewStopWordFilter(words []string) StopWordFilter {
+	set := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		set[w] = struct{}{}
+	}
+	return StopWordFilter{words: set}
+}
+
+func (StopWordFilter) Name() string { return "stop" }
+
+func (f StopWordFilter) Apply(token string) (string, bool) {
+	_, stop := f.words[token]
+	return token, !stop
+}
+
+type PorterStemFilter struct{}
+
+func (PorterStemFilter) Name() string { return "porter" }
+
+func (PorterStemFilter) Apply(token string) (string, bool) {
+	return PorterStem(token), true
+}
+
+// EnglishStopWords is the classic Lucene English stop set. It is kept short
+// on purpose: words like "go" or "c" are meaningful in a tech portfolio.
+var EnglishStopWords = []string{
+	"a", "an", "and", "are", "as", "at", "be", "but", "by", "for", "if", "in",
+	"into", "is", "it", "no", "not", "of", "on", "or", "such", "that", "the",
+	"their", "then", "there", "these", "they", "this", "to", "was", "will", "with",
+}
+
+// ShortTerms survive any minimum length: C and R are languages, and "C++"
+// and "C#" tokenize to "c".
+var ShortTerms = []string{"c", "r"}
+
+type AnalyzerOptions struct {
+	MinLength int
+	StopWords bool
+	Stem      bool
+}
+
+var DefaultAnalyzerOptions = AnalyzerOptions{MinLength: 2, StopWords: true, Stem: true}
+
+func NewAnalyzer(opts AnalyzerOptions) Analyzer {
+	filters := []TokenFilter{LowercaseFilter{}}
+	if opts.StopWords {
+		filters = append(filters, NewStopWordFilter(EnglishStopWords))
+	}
+	if opts.MinLength > 1 {
+		filters = append(filters, MinLengthFilter{Min: opts.MinLength, Keep: ShortTerms})
+	}
+	if opts.Stem {
+		filters = append(filters, PorterStemFilter{})
+	}
+	return &Pipeline{Tokenizer: WordTokenizer{}, Filters: filters}
+}
+
+var defaultAnalyzer = NewAnalyzer(DefaultAnalyzerOptions)
+
+// SetDefaultAnalyzer swaps the analyzer behind Tokenize. It is meant to be
+// called once at startup, before any request is served.
+func SetDefaultAnalyzer(a Analyzer) {
+	defaultAnalyzer = a
+}
+
+func DefaultAnalyzer() Analyzer {
+	return defaultAnalyzer
+}
@@ -0,0 +1,21 @@
+package util
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDefaultAnalyzerKeepsShortTechTerms(t *testing.T) {
+	analyzer := NewAnalyzer(DefaultAnalyzerOptions)
+
+	got := analyzer.Analyze("C and C++ and Go, R")
+	want := []string{"c", "c", "go", "r"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Analyze = %v, want %v", got, want)
+	}
+
+	// Other single letters are still too short to index.
+	if got := analyzer.Analyze("x y z"); len(got) != 0 {
+		t.Errorf("Analyze(x y z) = %v, want nothing", got)
+	}
+}
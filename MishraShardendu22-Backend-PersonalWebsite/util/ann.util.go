@@ -0,0 +1,140 @@
+package util
+
+import (
+	"math/rand"
+	"sort"
+)
+
+type Neighbor struct {
+	ID    string
+	Score float64
+}
+
+// LSHIndex is an approximate nearest-neighbour index over unit vectors. Each
+// table hashes a vector to the sign pattern of a few random hyperplanes, so
+// vectors at a small angle tend to share a bucket. A search only scores the
+// vectors in the query's buckets and those one bit away.
+type LSHIndex struct {
+	planes  [][][]float64 // table -> bit -> hyperplane
+	buckets []map[uint64][]string
+	vectors map[string][]float64
+}
+
+// NewLSHIndex builds an empty index for vectors of the given dimensions. The
+// seed fixes the hyperplanes, so the same corpus always buckets the same way.
+func NewLSHIndex(dims, tables, bits int, seed int64) *LSHIndex {
+	rng := rand.New(rand.NewSource(seed))
+	x := &LSHIndex{
+		planes:  make([][][]float64, tables),
+		buckets: make([]map[uint64][]string, tables),
+		vectors: make(map[string][]float64, 64),
+	}
+	for t := range x.planes {
+		x.planes[t] = make([][]float64, bits)
+		for b := range x.planes[t] {
+			plane := make([]float64, dims)
+			for i := range plane {
+				plane[i] = rng.NormFloat64()
+			}
+			x.planes[t][b] = plane
+		}
+		x.buckets[t] = make(map[uint64][]string, 16)
+	}
+	return x
+}
+
+func (x *LSHIndex) Len() int { return len(x.vectors) }
+
+func (x *LSHIndex) signature(table int, vec []float64) uint64 {
+	var sig uint64
+	for b, plane := range x.planes[table] {
+		if Cosine(plane, vec) >= 0 {
+			sig |= 1 << b
+		}
+	}
+	return sig
+}
+
+// Add stores vec under id, replacing any previous vector. Zero vectors carry
+// no direction and are not stored.
+func (x *LSHIndex) Add(id string, vec []float64) {
+	x.Remove(id)
+
+	var sumSquares float64
+	for _, v := range vec {
+		sumSquares += v * v
+	}
+	if sumSquares == 0 {
+		return
+	}
+
+	x.vectors[id] = vec
+	for t := range x.planes {
+		sig := x.signature(t, vec)
+		x.buckets[t][sig] = append(x.buckets[t][sig], id)
+	}
+}
+
+func (x *LSHIndex) Remove(id string) {
+	vec, ok := x.vectors[id]
+	if !ok {
+		return
+	}
+	for t := range x.planes {
+		sig := x.signature(t, vec)
+		ids := x.buckets[t][sig]
+		for i, other := range ids {
+			if other == id {
+				ids = append(ids[:i], ids[i+1:]...)
+				break
+			}
+		}
+		if len(ids) == 0 {
+			delete(x.buckets[t], sig)
+		} else {
+			x.buckets[t][sig] = ids
+		}
+	}
+	delete(x.vectors, id)
+}
+
+// Search returns up to k stored vectors most similar to query, best first.
+// When the probed buckets hold fewer than k vectors it scans everything, so
+// small indexes always answer exactly.
+func (x *LSHIndex) Search(query []float64, k int) []Neighbor {
+	candidates := make(map[string]struct{}, 4*k)
+	for t, planes := range x.planes {
+		sig := x.signature(t, query)
+		probe := func(s uint64) {
+			for _, id := range x.buckets[t][s] {
+				candidates[id] = struct{}{}
+			}
+		}
+		probe(sig)
+		for b := range planes {
+			probe(sig ^ 1<<b)
+		}
+	}
+
+	neighbors := make([]Neighbor, 0, len(candidates))
+	if len(candidates) < k {
+		for id, vec := range x.vectors {
+			neighbors = append(neighbors, Neighbor{ID: id, Score: Cosine(query, vec)})
+		}
+	} else {
+		for id := range candidates {
+			neighbors = append(neighbors, Neighbor{ID: id, Score: Cosine(query, x.vectors[id])})
+		}
+	}
+
+	sort.Slice(neighbors, func(i, j int) bool {
+		if neighbors[i].Score != neighbors[j].Score {
+			return neighbors[i].Score > neighbors[j].Score
+		}
+		return neighbors[i].ID < neighbors[j].ID
+	})
+	if len(neighbors) > k {
+		neighbors = neighbors[:k]
+	}
+	return neighbors
+}
@@ -0,0 +1,54 @@
+package util
+
+import (
+	"strconv"
+	"time"
+	"unicode"
+)
+
+const maxYearSpan = 60
+
+// ExtractYear finds the first plausible four digit year in a free-form date
+// string such as "2024-03-01", "Mar 2024" or "03/2024".
+func ExtractYear(date string) (int, bool) {
+	runes := []rune(date)
+	for i := 0; i+4 <= len(runes); i++ {
+		if i > 0 && unicode.IsDigit(runes[i-1]) {
+			continue
+		}
+		if i+4 < len(runes) && unicode.IsDigit(runes[i+4]) {
+			continue
+		}
+		year, err := strconv.Atoi(string(runes[i : i+4]))
+		if err == nil && year >= 1900 && year <= 2100 {
+			return year, true
+		}
+	}
+	return 0, false
+}
+
+// YearSpan lists every year covered by a start/end date pair. An empty or
+// unparseable end date (e.g. "Present") is treated as still ongoing.
+func YearSpan(start, end string) []int {
+	from, ok := ExtractYear(start)
+	if !ok {
+		return nil
+	}
+
+	to, ok := ExtractYear(end)
+	if !ok {
+		to = time.Now().Year()
+	}
+	if to < from {
+		to = from
+	}
+	if to-from > maxYearSpan {
+		to = from + maxYearSpan
+	}
+
+	years := make([]int, 0, to-from+1)
+	for y := from; y <= to; y++ {
+		years = append(years, y)
+	}
+	return years
+}
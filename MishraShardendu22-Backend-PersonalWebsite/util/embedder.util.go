@@ -0,0 +1,294 @@
+package util
+
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+)
+
+// Embedder maps analyzed tokens to a dense vector so that texts about the
+// same thing land close together even when they share no words.
+type Embedder interface {
+	Embed(tokens []string) []float64
+	Dimensions() int
+}
+
+// EmbedderFactory builds an Embedder for a corpus of analyzed documents.
+// Embedders that learn nothing from the corpus may ignore it.
+type EmbedderFactory func(corpus [][]string) Embedder
+
+// HashingEmbedder projects tokens and their character trigrams into a fixed
+// number of dimensions with signed feature hashing. It needs no training, so
+// related word forms ("scale", "scalability") still overlap.
+type HashingEmbedder struct {
+	dims int
+}
+
+func NewHashingEmbedder(dims int) *HashingEmbedder {
+	return &HashingEmbedder{dims: dims}
+}
+
+func (h *HashingEmbedder) Dimensions() int { return h.dims }
+
+func (h *HashingEmbedder) Embed(tokens []string) []float64 {
+	vec := make([]float64, h.dims)
+	for _, token := range tokens {
+		h.add(vec, token, 1)
+		for _, gram := range Trigrams(token) {
+			h.add(vec, gram, 0.5)
+		}
+	}
+	Normalize(vec)
+	return vec
+}
+
+func (h *HashingEmbedder) add(vec []float64, feature string, weight float64) {
+	hasher := fnv.New64a()
+	hasher.Write([]byte(feature))
+	sum := hasher.Sum64()
+	if sum>>63 == 1 {
+		weight = -weight
+	}
+	vec[sum%uint64(h.dims)] += weight
+}
+
+func HashingEmbedderFactory(dims int) EmbedderFactory {
+	return func([][]string) Embedder { return NewHashingEmbedder(dims) }
+}
+
+// LSAEmbedder is latent semantic analysis: a truncated SVD of the corpus
+// TF-IDF matrix. Terms that occur in the same documents share latent
+// dimensions, so a query can match a document through co-occurring words.
+type LSAEmbedder struct {
+	vocab map[string]int
+	idf   []float64
+	basis [][]float64 // left singular vectors, one per latent dimension
+}
+
+// NewLSAEmbedder fits up to dims latent dimensions to the corpus. The SVD is
+// taken through the document Gram matrix, which is cheap while the corpus has
+// a few hundred documents.
+func NewLSAEmbedder(corpus [][]string, dims int) *LSAEmbedder {
+	lsa := &LSAEmbedder{vocab: make(map[string]int, 512)}
+
+	df := make([]int, 0, 512)
+	for _, doc := range corpus {
+		seen := make(map[string]struct{}, len(doc))
+		for _, token := range doc {
+			if _, ok := seen[token]; ok {
+				continue
+			}
+			seen[token] = struct{}{}
+			id, ok := lsa.vocab[token]
+			if !ok {
+				id = len(df)
+				lsa.vocab[token] = id
+				df = append(df, 0)
+			}
+			df[id]++
+		}
+	}
+
+	n := float64(len(corpus))
+	lsa.idf = make([]float64, len(df))
+	for id, count := range df {
+		lsa.idf[id] = math.Log((n+1)/(float64(count)+1)) + 1
+	}
+
+	docs := make([]map[int]float64, len(corpus))
+	for i, doc := range corpus {
+		docs[i] = lsa.weigh(doc)
+	}
+
+	gram := make([][]float64, len(docs))
+	for i := range docs {
+		gram[i] = make([]float64, len(docs))
+	}
+	for i := range docs {
+		for j := i; j < len(docs); j++ {
+			dot := sparseDot(docs[i], docs[j])
+			gram[i][j], gram[j][i] = dot, dot
+		}
+	}
+
+	values, vectors := SymmetricEigen(gram)
+	for k := 0; k < len(values) && len(lsa.basis) < dims; k++ {
+		if values[k] <= 1e-9 {
+			break
+		}
+		// u_k = A v_k / sigma_k
+		sigma := math.Sqrt(values[k])
+		u := make([]float64, len(df))
+		for j, doc := range docs {
+			if vectors[k][j] == 0 {
+				continue
+			}
+			for id, w := range doc {
+				u[id] += w * vectors[k][j] / sigma
+			}
+		}
+		lsa.basis = append(lsa.basis, u)
+	}
+	return lsa
+}
+
+func LSAEmbedderFactory(dims int) EmbedderFactory {
+	return func(corpus [][]string) Embedder { return NewLSAEmbedder(corpus, dims) }
+}
+
+func (l *LSAEmbedder) Dimensions() int { return len(l.basis) }
+
+// Embed folds the tokens into the latent space. Unknown terms are ignored.
+func (l *LSAEmbedder) Embed(tokens []string) []float64 {
+	weights := l.weigh(tokens)
+	vec := make([]float64, len(l.basis))
+	for k, u := range l.basis {
+		for id, w := range weights {
+			vec[k] += u[id] * w
+		}
+	}
+	Normalize(vec)
+	return vec
+}
+
+// weigh returns the unit-length TF-IDF vector of the known tokens.
+func (l *LSAEmbedder) weigh(tokens []string) map[int]float64 {
+	counts := make(map[int]int, len(tokens))
+	for _, token := range tokens {
+		if id, ok := l.vocab[token]; ok {
+			counts[id]++
+		}
+	}
+
+	weights := make(map[int]float64, len(counts))
+	var sumSquares float64
+	for id, tf := range counts {
+		w := (1 + math.Log(float64(tf))) * l.idf[id]
+		weights[id] = w
+		sumSquares += w * w
+	}
+	if norm := math.Sqrt(sumSquares); norm > 0 {
+		for id := range weights {
+			weights[id] /= norm
+		}
+	}
+	return weights
+}
+
+func sparseDot(a, b map[int]float64) float64 {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	var dot float64
+	for id, w := range a {
+		dot += w * b[id]
+	}
+	return dot
+}
+
+// SymmetricEigen diagonalises a symmetric matrix with cyclic Jacobi
+// rotations. Eigenvalues are returned in descending order, each with its
+// eigenvector in the matching row of vectors.
+func SymmetricEigen(m [][]float64) (values []float64, vectors [][]float64) {
+	n := len(m)
+	a := make([][]float64, n)
+	v := make([][]float64, n)
+	for i := range m {
+		a[i] = append([]float64(nil), m[i]...)
+		v[i] = make([]float64, n)
+		v[i][i] = 1
+	}
+
+	for sweep := 0; sweep < 50; sweep++ {
+		var off float64
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				off += a[i][j] * a[i][j]
+			}
+		}
+		if off < 1e-18 {
+			break
+		}
+
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				if math.Abs(a[p][q]) < 1e-15 {
+					continue
+				}
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := math.Copysign(1, theta) / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+
+				for k := 0; k < n; k++ {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p] = c*akp - s*akq
+					a[k][q] = s*akp + c*akq
+				}
+				for k := 0; k < n; k++ {
+					apk, aqk := a[p][k], a[q][k]
+					a[p][k] = c*apk - s*aqk
+					a[q][k] = s*apk + c*aqk
+				}
+				for k := 0; k < n; k++ {
+					vkp, vkq := v[k][p], v[k][q]
+					v[k][p] = c*vkp - s*vkq
+					v[k][q] = s*vkp + c*vkq
+				}
+			}
+		}
+	}
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return a[order[i]][order[i]] > a[order[j]][order[j]] })
+
+	values = make([]float64, n)
+	vectors = make([][]float64, n)
+	for rank, col := range order {
+		values[rank] = a[col][col]
+		vectors[rank] = make([]float64, n)
+		for k := 0; k < n; k++ {
+			vectors[rank][k] = v[k][col]
+		}
+	}
+	return values, vectors
+}
+
+// Normalize scales vec to unit length in place. Zero vectors are left as is.
+func Normalize(vec []float64) {
+	var sumSquares float64
+	for _, x := range vec {
+		sumSquares += x * x
+	}
+	if sumSquares == 0 {
+		return
+	}
+	norm := math.Sqrt(sumSquares)
+	for i := range vec {
+		vec[i] /= norm
+	}
+}
+
+// Cosine returns the cosine similarity of two unit vectors.
+func Cosine(a, b []float64) float64 {
+	var dot float64
+	for i := range min(len(a), len(b)) {
+		dot += a[i] * b[i]
+	}
+	return dot
+}
+
+var defaultEmbedderFactory = LSAEmbedderFactory(64)
+
+// SetDefaultEmbedderFactory swaps the embedder used by semantic search. It is
+// meant to be called once at startup, before any request is served.
+func SetDefaultEmbedderFactory(f EmbedderFactory) {
+	defaultEmbedderFactory = f
+}
+
+func DefaultEmbedderFactory() EmbedderFactory {
+	return defaultEmbedderFactory
+}
@@ -1,6 +1,9 @@
 package util
 
-import "os"
+import (
+	"os"
+	"strconv"
+)
 
 func GetEnv(key, fallback string) string {
 	if value := os.Getenv(key); value != "" {
@@ -8,3 +11,17 @@ func GetEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func GetEnvInt(key string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
+
+func GetEnvBool(key string, fallback bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
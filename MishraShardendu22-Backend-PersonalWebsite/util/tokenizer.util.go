@@ -2,35 +2,23 @@ package util
 
 import (
 	"strings"
-	"unicode"
 )
 
-func Tokenize(text string) []string {
-	text = strings.ToLower(text)
-	estimatedTokens := len(text) / 6
-	if estimatedTokens < 8 {
-		estimatedTokens = 8
-	}
-	tokens := make([]string, 0, estimatedTokens)
-	var builder strings.Builder
-	builder.Grow(32)
-
-	for _, r := range text {
-		if unicode.IsLetter(r) || unicode.IsNumber(r) {
-			builder.WriteRune(r)
-		} else if builder.Len() > 0 {
-			if token := builder.String(); len(token) > 1 {
-				tokens = append(tokens, token)
-			}
-			builder.Reset()
-		}
-	}
+// TokenSpan is a token together with its rune offsets in the source text.
+type TokenSpan struct {
+	Token string
+	Start int
+	End   int
+}
 
-	if builder.Len() > 1 {
-		tokens = append(tokens, builder.String())
-	}
+func Tokenize(text string) []string {
+	return defaultAnalyzer.Analyze(text)
+}
 
-	return tokens
+// TokenSpans tokenizes text like Tokenize but keeps where each token came
+// from, so matches can be located in the original string.
+func TokenSpans(text string) []TokenSpan {
+	return defaultAnalyzer.AnalyzeSpans(text)
 }
 
 func GenerateTokens(fields []string, tags []string) []string {
@@ -0,0 +1,71 @@
+package util
+
+import (
+	"net/url"
+	"strings"
+)
+
+// NextPageURL returns the rel="next" target of an HTTP Link header, or ""
+// on the last page.
+func NextPageURL(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		target, params, ok := strings.Cut(part, ";")
+		if !ok {
+			continue
+		}
+		for _, param := range strings.Split(params, ";") {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(target), "<>")
+			}
+		}
+	}
+	return ""
+}
+
+// URLHostname returns the lowercased host of a link without a leading
+// "www.", or "" when there is none. Links without a scheme are accepted.
+func URLHostname(link string) string {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return ""
+	}
+	if !strings.Contains(link, "://") {
+		link = "//" + link
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// ParseRepoURL splits a repository link into its lowercased host and its
+// path, such as "github.com" and "owner/repo/tree/main". Links may omit the
+// scheme or use the scp-like git@host:owner/repo form; a trailing ".git" is
+// dropped.
+func ParseRepoURL(link string) (host, path string, ok bool) {
+	link = strings.TrimSpace(link)
+	if rest, found := strings.CutPrefix(link, "git@"); found {
+		host, path, found = strings.Cut(rest, ":")
+		if !found {
+			return "", "", false
+		}
+		link = host + "/" + path
+	}
+
+	host = URLHostname(link)
+	if host == "" {
+		return "", "", false
+	}
+	if !strings.Contains(link, "://") {
+		link = "//" + link
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", "", false
+	}
+
+	path = strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	return host, path, path != ""
+}
@@ -122,6 +122,14 @@ VOLUNTEER_RESPONSE=$(curl -s -X POST "$BACKEND_URL/api/volunteer/experiences" \
 
 echo "Response: $VOLUNTEER_RESPONSE"
 
+# Step 6: Rebuild the search index
+echo ""
+echo "Step 6: Rebuilding search tokens and index..."
+REINDEX_RESPONSE=$(curl -s -X POST "$BACKEND_URL/api/admin/search/reindex" \
+  -H "Authorization: Bearer $TOKEN")
+
+echo "Response: $REINDEX_RESPONSE"
+
 echo ""
 echo "=========================================="
 echo "✅ Sample data added successfully!"